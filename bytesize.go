@@ -0,0 +1,125 @@
+package goini
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ByteSize 字节大小，配置中可以写为 512、10KB、1.5GiB 等形式
+type ByteSize uint64
+
+// 十进制单位，KB=1000
+const (
+	KB ByteSize = 1000
+	MB          = KB * 1000
+	GB          = MB * 1000
+	TB          = GB * 1000
+	PB          = TB * 1000
+)
+
+// 二进制单位，KiB=1024
+const (
+	KiB ByteSize = 1024
+	MiB          = KiB * 1024
+	GiB          = MiB * 1024
+	TiB          = GiB * 1024
+	PiB          = TiB * 1024
+)
+
+// 单位名称，不区分大小写，K、M 等同于 KB、MB
+var byteUnits = map[string]ByteSize{
+	"":    1,
+	"b":   1,
+	"k":   KB,
+	"kb":  KB,
+	"kib": KiB,
+	"m":   MB,
+	"mb":  MB,
+	"mib": MiB,
+	"g":   GB,
+	"gb":  GB,
+	"gib": GiB,
+	"t":   TB,
+	"tb":  TB,
+	"tib": TiB,
+	"p":   PB,
+	"pb":  PB,
+	"pib": PiB,
+}
+
+// 输出时使用的单位，从大到小
+var byteUnitNames = []struct {
+	name string
+	size ByteSize
+}{
+	{"PiB", PiB}, {"PB", PB}, {"TiB", TiB}, {"TB", TB}, {"GiB", GiB},
+	{"GB", GB}, {"MiB", MiB}, {"MB", MB}, {"KiB", KiB}, {"KB", KB},
+}
+
+/**
+ * 解析字节大小，不带单位时为字节数，KB、MB 等为十进制单位，KiB、MiB 等为二进制单位
+ * @param valStr string 如 10MB、1.5GiB、512
+ * @return ByteSize, error 格式错误时为 strconv.ErrSyntax，超出范围时为 strconv.ErrRange
+ */
+func ParseByteSize(valStr string) (ByteSize, error) {
+	match := rxByteSize.FindStringSubmatch(strings.TrimSpace(valStr))
+	if match == nil {
+		return 0, byteSizeError(valStr, strconv.ErrSyntax)
+	}
+
+	unit, ok := byteUnits[strings.ToLower(match[2])]
+	if !ok {
+		return 0, byteSizeError(valStr, strconv.ErrSyntax)
+	}
+
+	// 整数精确计算，小数按浮点数计算
+	if !strings.Contains(match[1], ".") {
+		n, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil || n > math.MaxUint64/uint64(unit) {
+			return 0, byteSizeError(valStr, strconv.ErrRange)
+		}
+
+		return ByteSize(n) * unit, nil
+	}
+
+	f, err := strconv.ParseFloat(match[1], 64)
+	if err != nil || f*float64(unit) >= math.MaxUint64 {
+		return 0, byteSizeError(valStr, strconv.ErrRange)
+	}
+
+	return ByteSize(f * float64(unit)), nil
+}
+
+// 解析失败的错误，与 strconv 的错误一致，可以用 errors.Is(err, strconv.ErrRange) 判断
+func byteSizeError(valStr string, err error) error {
+	return &strconv.NumError{Func: "ParseByteSize", Num: valStr, Err: err}
+}
+
+// UnmarshalText 实现 encoding.TextUnmarshaler
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	size, err := ParseByteSize(string(text))
+	if err != nil {
+		return err
+	}
+
+	*b = size
+
+	return nil
+}
+
+// MarshalText 实现 encoding.TextMarshaler
+func (b ByteSize) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// 使用可以整除的最大单位输出，如 10MB、1GiB，无法整除时输出字节数
+func (b ByteSize) String() string {
+	for _, unit := range byteUnitNames {
+		if b >= unit.size && b%unit.size == 0 {
+			return strconv.FormatUint(uint64(b/unit.size), 10) + unit.name
+		}
+	}
+
+	return strconv.FormatUint(uint64(b), 10) + "B"
+}
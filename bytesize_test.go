@@ -0,0 +1,57 @@
+package goini
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestParseByteSize(t *testing.T) {
+	testCases := []struct {
+		Value  string
+		Expect ByteSize
+		Err    error
+	}{
+		{Value: "512", Expect: 512},
+		{Value: "512B", Expect: 512},
+		{Value: "10KB", Expect: 10000},
+		{Value: "10kb", Expect: 10000},
+		{Value: "10K", Expect: 10000},
+		{Value: "10KiB", Expect: 10240},
+		{Value: "10MB", Expect: 10000000},
+		{Value: "10 MiB", Expect: 10485760},
+		{Value: "1.5GiB", Expect: 1610612736},
+		{Value: "2GB", Expect: 2000000000},
+		{Value: "1TiB", Expect: 1099511627776},
+		{Value: "16PiB", Expect: 16 * PiB},
+		{Value: "100000PiB", Err: strconv.ErrRange},
+		{Value: "10XB", Err: strconv.ErrSyntax},
+		{Value: "-1MB", Err: strconv.ErrSyntax},
+		{Value: "MB", Err: strconv.ErrSyntax},
+	}
+
+	for _, v := range testCases {
+		ret, err := ParseByteSize(v.Value)
+		if ret != v.Expect || !errors.Is(err, v.Err) {
+			t.Errorf("Goini: Not as expected ret=%v, err=%v, expect=%v", ret, err, v.Expect)
+		}
+	}
+
+	strCases := []struct {
+		Size   ByteSize
+		Expect string
+	}{
+		{Size: 0, Expect: "0B"},
+		{Size: 512, Expect: "512B"},
+		{Size: 10 * MB, Expect: "10MB"},
+		{Size: 10 * MiB, Expect: "10MiB"},
+		{Size: 1536, Expect: "1536B"},
+		{Size: 3 * GiB, Expect: "3GiB"},
+	}
+
+	for _, v := range strCases {
+		if ret := v.Size.String(); ret != v.Expect {
+			t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, v.Expect)
+		}
+	}
+}
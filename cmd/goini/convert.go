@@ -0,0 +1,84 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/vcqr/goini"
+)
+
+/**
+ * 转换配置格式，未指定文件时从标准输入读取
+ *
+ *	goini convert --from ini --to toml [-o app.toml] app.ini
+ *
+ * 目标格式无法表示的节点输出警告到标准错误，出错时退出码为2
+ */
+func runConvert(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("convert", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	from := flags.String("from", "", "source syntax: ini, yml, toml or json, detected when empty")
+	to := flags.String("to", "", "target syntax: ini, yml, toml or json")
+	output := flags.String("o", "", "output file, stdout when empty")
+
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+
+	if *to == "" || flags.NArg() > 1 {
+		fmt.Fprintln(stderr, "usage: goini convert [--from syntax] --to syntax [-o file] [file]")
+		return exitError
+	}
+
+	var data []byte
+	var err error
+
+	file := flags.Arg(0)
+	if file == "" || file == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(file)
+	}
+
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	// 根据扩展名推断源格式
+	if *from == "" && file != "" && file != "-" {
+		if strings.EqualFold(filepath.Ext(file), ".json") {
+			*from = "json"
+		} else if !strings.HasPrefix(strings.TrimSpace(string(data)), "{") {
+			*from = goini.DetectSyntax(file, data)
+		}
+	}
+
+	ret, warnings, err := goini.Convert(data, *from, *to)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	for _, warning := range warnings {
+		fmt.Fprintln(stderr, "goini: warning: "+warning.String())
+	}
+
+	if *output == "" {
+		stdout.Write(ret)
+		return exitOK
+	}
+
+	if err := ioutil.WriteFile(*output, ret, 0644); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	return exitOK
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/vcqr/goini"
+)
+
+/**
+ * 比较两个配置解析后的值，可以比较不同格式的文件
+ *
+ *	goini diff [-o json] old.ini new.toml
+ *
+ * 没有变化时退出码为0，有变化时为1，出错时为2
+ */
+func runDiff(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	output := flags.String("o", "text", "output format, text or json")
+
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+
+	if flags.NArg() != 2 {
+		fmt.Fprintln(stderr, "usage: goini diff [-o text|json] old new")
+		return exitError
+	}
+
+	if *output != "text" && *output != "json" {
+		fmt.Fprintf(stderr, "goini: unknown output format %q\n", *output)
+		return exitError
+	}
+
+	configs := make([]*goini.Goini, 0, 2)
+	for _, file := range flags.Args() {
+		config, err := goini.LoadFile(file, "")
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitError
+		}
+
+		configs = append(configs, config)
+	}
+
+	changes := goini.Diff(configs[0], configs[1])
+
+	code := exitOK
+	if len(changes) > 0 {
+		code = exitFailed
+	}
+
+	if *output == "json" {
+		if changes == nil {
+			changes = []goini.Change{}
+		}
+
+		data, err := json.MarshalIndent(changes, "", "  ")
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitError
+		}
+
+		fmt.Fprintln(stdout, string(data))
+		return code
+	}
+
+	for _, change := range changes {
+		fmt.Fprintln(stdout, change.String())
+	}
+
+	return code
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/vcqr/goini"
+)
+
+/**
+ * 查询节点的值，变量及继承的节与 Goini.Get 的规则相同
+ *
+ *	goini get -f app.ini -s database [-o json] db.host
+ *
+ * 找到节点时退出码为0，未找到时为1，出错时为2
+ */
+func runGet(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("get", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	file := flags.String("f", "", "config file path")
+	section := flags.String("s", "default", "section name")
+	output := flags.String("o", "text", "output format, text or json")
+
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+
+	if *file == "" || flags.NArg() != 1 {
+		fmt.Fprintln(stderr, "usage: goini get -f file [-s section] [-o text|json] key")
+		return exitError
+	}
+
+	if *output != "text" && *output != "json" {
+		fmt.Fprintf(stderr, "goini: unknown output format %q\n", *output)
+		return exitError
+	}
+
+	config, err := goini.LoadFile(*file, "")
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	val := config.Resolve(flags.Arg(0), *section)
+	if val == nil {
+		return exitNotFound
+	}
+
+	if err := writeValue(stdout, val, *output); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	return exitOK
+}
+
+// 输出值，文本格式下字符串原样输出，map及切片输出为json
+func writeValue(w io.Writer, val interface{}, output string) error {
+	if valStr, ok := val.(string); ok && output == "text" {
+		_, err := fmt.Fprintln(w, valStr)
+		return err
+	}
+
+	data, err := json.Marshal(val)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(w, string(data))
+
+	return err
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/vcqr/goini"
+)
+
+/**
+ * 检查配置文件
+ *
+ *	goini lint [-strict] [-o json] app.ini conf.d/*.ini
+ *
+ * 存在错误时退出码为1，-strict 时警告也视为错误，参数错误时为2
+ */
+func runLint(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("lint", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+
+	strict := flags.Bool("strict", false, "treat warnings as errors")
+	output := flags.String("o", "text", "output format, text or json")
+
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+
+	if flags.NArg() == 0 {
+		fmt.Fprintln(stderr, "usage: goini lint [-strict] [-o text|json] file...")
+		return exitError
+	}
+
+	if *output != "text" && *output != "json" {
+		fmt.Fprintf(stderr, "goini: unknown output format %q\n", *output)
+		return exitError
+	}
+
+	diags := []goini.Diagnostic{}
+	for _, file := range flags.Args() {
+		diags = append(diags, goini.Lint(file)...)
+	}
+
+	code := exitOK
+	for _, diag := range diags {
+		if diag.Severity == goini.SeverityError || *strict {
+			code = exitFailed
+		}
+	}
+
+	if *output == "json" {
+		data, err := json.MarshalIndent(diags, "", "  ")
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitError
+		}
+
+		fmt.Fprintln(stdout, string(data))
+		return code
+	}
+
+	for _, diag := range diags {
+		fmt.Fprintln(stdout, diag.String())
+	}
+
+	return code
+}
@@ -0,0 +1,71 @@
+// goini 命令行工具，用于查询配置文件
+//
+//	goini get -f app.ini -s database db.host
+//	goini convert --from ini --to toml app.ini
+//	goini lint app.ini
+//	goini diff old.ini new.toml
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// 退出码
+const (
+	exitOK       = 0 // 成功，或找到了节点
+	exitNotFound = 1 // 未找到节点
+	exitFailed   = 1 // 检查发现了问题，或配置有变化
+	exitError    = 2 // 参数或文件错误
+)
+
+// 子命令
+var commands = map[string]func(args []string, stdout, stderr io.Writer) int{
+	"get":     runGet,
+	"convert": runConvert,
+	"lint":    runLint,
+	"diff":    runDiff,
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+/**
+ * 执行子命令
+ * @param args []string 命令行参数，不含程序名
+ * @return int 退出码
+ */
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return exitError
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "goini: unknown command %q\n", args[0])
+		usage(stderr)
+		return exitError
+	}
+
+	return cmd(args[1:], stdout, stderr)
+}
+
+// 输出使用说明
+func usage(w io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "usage: goini <command> [options]")
+	fmt.Fprintln(w, "commands:")
+	for _, name := range names {
+		fmt.Fprintln(w, "  "+name)
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestGet(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.ini")
+	ioutil.WriteFile(path, []byte("env = prod\n[database]\ndb.host = 127.0.0.1\ndb.port = 3306\ndb.dsn = ${database:db.host}:${database:db.port}\n[replica:database]\ndb.host = 10.0.0.2\n"), 0644)
+
+	testCases := []struct {
+		Args   []string
+		Expect string
+		Code   int
+	}{
+		{Args: []string{"get", "-f", path, "env"}, Expect: "prod\n", Code: exitOK},
+		{Args: []string{"get", "-f", path, "-s", "database", "db.host"}, Expect: "127.0.0.1\n", Code: exitOK},
+		{Args: []string{"get", "-f", path, "-s", "replica", "db.port"}, Expect: "3306\n", Code: exitOK},
+		{Args: []string{"get", "-f", path, "-s", "database", "db.dsn"}, Expect: "127.0.0.1:3306\n", Code: exitOK},
+		{Args: []string{"get", "-f", path, "-s", "database", "-o", "json", "db"}, Expect: "{\"dsn\":\"127.0.0.1:3306\",\"host\":\"127.0.0.1\",\"port\":\"3306\"}\n", Code: exitOK},
+		{Args: []string{"get", "-f", path, "missing"}, Expect: "", Code: exitNotFound},
+		{Args: []string{"get", "-f", filepath.Join(filepath.Dir(path), "missing.ini"), "env"}, Expect: "", Code: exitError},
+		{Args: []string{"get", "env"}, Expect: "", Code: exitError},
+		{Args: []string{"unknown"}, Expect: "", Code: exitError},
+	}
+
+	for _, v := range testCases {
+		stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+
+		if code := run(v.Args, stdout, stderr); code != v.Code {
+			t.Errorf("Goini: Not as expected code=%v, expect=%v", code, v.Code)
+		}
+
+		if stdout.String() != v.Expect {
+			t.Errorf("Goini: Not as expected ret=%q, expect=%q", stdout.String(), v.Expect)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "app.ini")
+	ioutil.WriteFile(path, []byte("env = prod\n[db]\nport = 3306\n"), 0644)
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	if code := run([]string{"convert", "--from", "ini", "--to", "toml", path}, stdout, stderr); code != exitOK {
+		t.Errorf("Goini: Not as expected code=%v, expect=%v", code, exitOK)
+	}
+
+	expect := "env = \"prod\"\n\n[db]\nport = 3306\n"
+	if stdout.String() != expect {
+		t.Errorf("Goini: Not as expected ret=%q, expect=%q", stdout.String(), expect)
+	}
+
+	output := filepath.Join(dir, "app.json")
+	if code := run([]string{"convert", "--to", "json", "-o", output, path}, stdout, stderr); code != exitOK {
+		t.Errorf("Goini: Not as expected code=%v, expect=%v", code, exitOK)
+	}
+
+	expect = "{\n  \"db\": {\n    \"port\": 3306\n  },\n  \"env\": \"prod\"\n}\n"
+	if data, _ := ioutil.ReadFile(output); string(data) != expect {
+		t.Errorf("Goini: Not as expected ret=%q, expect=%q", data, expect)
+	}
+
+	if code := run([]string{"convert", "--from", "xml", "--to", "ini", path}, stdout, stderr); code != exitError {
+		t.Errorf("Goini: Not as expected code=%v, expect=%v", code, exitError)
+	}
+}
+
+func TestLint(t *testing.T) {
+	dir := t.TempDir()
+
+	valid := filepath.Join(dir, "valid.ini")
+	ioutil.WriteFile(valid, []byte("env = prod\n[db]\nport = 3306\n"), 0644)
+
+	warning := filepath.Join(dir, "warning.ini")
+	ioutil.WriteFile(warning, []byte("env = prod\nenv = dev\n"), 0644)
+
+	invalid := filepath.Join(dir, "invalid.ini")
+	ioutil.WriteFile(invalid, []byte("[db\n"), 0644)
+
+	testCases := []struct {
+		Args   []string
+		Expect string
+		Code   int
+	}{
+		{Args: []string{"lint", valid}, Expect: "", Code: exitOK},
+		{Args: []string{"lint", warning}, Expect: warning + ":2:1: warning: duplicate key \"env\", first defined on line 1\n", Code: exitOK},
+		{Args: []string{"lint", "-strict", warning}, Expect: warning + ":2:1: warning: duplicate key \"env\", first defined on line 1\n", Code: exitFailed},
+		{Args: []string{"lint", valid, invalid}, Expect: invalid + ":1:1: error: section is not closed\n", Code: exitFailed},
+		{Args: []string{"lint"}, Expect: "", Code: exitError},
+	}
+
+	for _, v := range testCases {
+		stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+
+		if code := run(v.Args, stdout, stderr); code != v.Code {
+			t.Errorf("Goini: Not as expected code=%v, expect=%v", code, v.Code)
+		}
+
+		if stdout.String() != v.Expect {
+			t.Errorf("Goini: Not as expected ret=%q, expect=%q", stdout.String(), v.Expect)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	dir := t.TempDir()
+
+	oldPath := filepath.Join(dir, "old.ini")
+	ioutil.WriteFile(oldPath, []byte("env = prod\n[db]\nport = 3306\n"), 0644)
+
+	newPath := filepath.Join(dir, "new.toml")
+	ioutil.WriteFile(newPath, []byte("env = \"prod\"\n\n[db]\nport = 3307\n"), 0644)
+
+	testCases := []struct {
+		Args   []string
+		Expect string
+		Code   int
+	}{
+		{Args: []string{"diff", oldPath, oldPath}, Expect: "", Code: exitOK},
+		{Args: []string{"diff", oldPath, newPath}, Expect: "~ [db] port: 3306 -> 3307\n", Code: exitFailed},
+		{Args: []string{"diff", oldPath}, Expect: "", Code: exitError},
+	}
+
+	for _, v := range testCases {
+		stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+
+		if code := run(v.Args, stdout, stderr); code != v.Code {
+			t.Errorf("Goini: Not as expected code=%v, expect=%v", code, v.Code)
+		}
+
+		if stdout.String() != v.Expect {
+			t.Errorf("Goini: Not as expected ret=%q, expect=%q", stdout.String(), v.Expect)
+		}
+	}
+}
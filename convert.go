@@ -0,0 +1,379 @@
+package goini
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 转换时目标格式无法表示的内容，对应的节点不会写入
+type Warning struct {
+	Section string
+	Key     string
+	Message string
+}
+
+func (w Warning) String() string {
+	if w.Section == "" || w.Section == defaultName {
+		return w.Key + ": " + w.Message
+	}
+
+	return "[" + w.Section + "] " + w.Key + ": " + w.Message
+}
+
+/**
+ * 转换配置格式，支持 ini、yml、toml 及 json，继承的节及变量会先被解析
+ * @param data []byte 配置内容
+ * @param from string 源格式，为空时根据内容推断
+ * @param to string 目标格式
+ * @return []byte, []Warning, error
+ */
+func Convert(data []byte, from, to string) ([]byte, []Warning, error) {
+	if from == "" && json.Valid(data) && bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		from = "json"
+	}
+
+	var config *Goini
+	var err error
+
+	if from == "json" {
+		config, err = loadJSON(data)
+	} else {
+		if _, ok := syntaxMap[from]; !ok {
+			return nil, nil, fmt.Errorf("goini: unsupported syntax %q", from)
+		}
+
+		config, err = LoadBytes(data, from)
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return config.Export(to)
+}
+
+/**
+ * 以指定格式导出配置，继承的节及变量会先被解析
+ * ini的节在其他格式中写为第一层的表格，其他格式第一层的表格在ini中写为节
+ * @param syntax string 目标格式，ini、yml、toml 或 json
+ * @return []byte, []Warning, error
+ */
+func (goini *Goini) Export(syntax string) ([]byte, []Warning, error) {
+	targetSyntax, ok := syntaxMap[syntax]
+	if syntax == "json" {
+		targetSyntax, ok = "json", true
+	}
+
+	if !ok || syntax == "" {
+		return nil, nil, fmt.Errorf("goini: unsupported syntax %q", syntax)
+	}
+
+	goini.mu.RLock()
+	sourceSyntax := goini.Syntax
+	sections, warnings := goini.resolvedSections()
+	goini.mu.RUnlock()
+
+	if targetSyntax == "ini" {
+		if sourceSyntax != "ini" {
+			promoteSections(sections)
+		}
+
+		data, iniWarnings := exportIni(sections)
+		return data, append(warnings, iniWarnings...), nil
+	}
+
+	// 默认节的节点放在第一层，其他节写为表格
+	tree := sections[defaultName]
+	if tree == nil {
+		tree = make(map[string]interface{})
+	}
+
+	names := sectionNames(sections)
+	for _, name := range names[1:] {
+		if _, ok := tree[name]; ok {
+			warnings = append(warnings, Warning{Key: name, Message: "conflicts with section [" + name + "]"})
+		}
+
+		tree[name] = sections[name]
+	}
+
+	buf := &bytes.Buffer{}
+
+	if targetSyntax == "json" {
+		data, err := json.MarshalIndent(typedValue(tree), "", "  ")
+		if err != nil {
+			return nil, nil, err
+		}
+
+		buf.Write(data)
+		buf.WriteString("\n")
+
+		return buf.Bytes(), warnings, nil
+	}
+
+	val, err := marshalValue(reflect.ValueOf(typedValue(tree)), "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if targetSyntax == "yml" {
+		err = writeYaml(buf, val.(marshalObject), "")
+	} else {
+		err = writeToml(buf, val.(marshalObject), "")
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return buf.Bytes(), warnings, nil
+}
+
+// 解析继承的节及变量，每个节的节点还原为嵌套的map，调用方需持有锁
+func (goini *Goini) resolvedSections() (map[string]map[string]interface{}, []Warning) {
+	var warnings []Warning
+
+	sections := make(map[string]map[string]interface{}, len(goini.sections))
+	for name, val := range goini.sections {
+		property, _ := goini.resolveValue(val).(map[string]interface{})
+
+		leaves := make(map[string]interface{})
+		flattenValue("", property, leaves)
+
+		tree, conflicts := nestLeaves(leaves)
+		for _, key := range conflicts {
+			warnings = append(warnings, Warning{Section: name, Key: key, Message: "conflicts with a nested key"})
+		}
+
+		sections[name] = tree
+	}
+
+	return sections, warnings
+}
+
+// 默认节中第一层的表格写为节，已存在同名的节时保持不变
+func promoteSections(sections map[string]map[string]interface{}) {
+	property := sections[defaultName]
+	for _, key := range sortedKeys(property) {
+		child, ok := property[key].(map[string]interface{})
+		if _, exists := sections[key]; !ok || exists {
+			continue
+		}
+
+		sections[key] = child
+		delete(property, key)
+	}
+}
+
+// 导出为ini格式
+func exportIni(sections map[string]map[string]interface{}) ([]byte, []Warning) {
+	var warnings []Warning
+
+	// 只保留ini可以表示的节点
+	flatSections := make(map[string]interface{}, len(sections))
+	for name, property := range sections {
+		leaves := make(map[string]interface{})
+		flattenValue("", property, leaves)
+
+		for _, key := range sortedKeys(leaves) {
+			if msg := iniMessage(leaves[key]); msg != "" {
+				warnings = append(warnings, Warning{Section: name, Key: key, Message: msg})
+				delete(leaves, key)
+			}
+		}
+
+		flatSections[name] = leaves
+	}
+
+	buf, _ := encodeIni(flatSections)
+
+	return buf.Bytes(), warnings
+}
+
+// ini无法表示的值，返回原因
+func iniMessage(val interface{}) string {
+	switch v := val.(type) {
+	case []map[string]interface{}:
+		return "array of tables is not supported in ini"
+	case []interface{}:
+		for _, item := range v {
+			if msg := iniMessage(item); msg != "" {
+				return msg
+			}
+
+			if _, ok := item.(map[string]interface{}); ok {
+				return "array of tables is not supported in ini"
+			}
+		}
+	case string:
+		if strings.ContainsAny(v, "\r\n") {
+			return "multi-line string is not supported in ini"
+		}
+	}
+
+	if _, err := formatIniValue(val); err != nil {
+		return err.Error()
+	}
+
+	return ""
+}
+
+// 以.分隔的key还原为嵌套的map，返回无法还原的key
+func nestLeaves(leaves map[string]interface{}) (map[string]interface{}, []string) {
+	tree := make(map[string]interface{})
+
+	var conflicts []string
+
+	for _, key := range sortedKeys(leaves) {
+		keyArr := strings.Split(key, ".")
+		node := tree
+		ok := true
+
+		for _, k := range keyArr[:len(keyArr)-1] {
+			child, exists := node[k]
+			if !exists {
+				child = make(map[string]interface{})
+				node[k] = child
+			}
+
+			if node, ok = child.(map[string]interface{}); !ok {
+				break
+			}
+		}
+
+		last := keyArr[len(keyArr)-1]
+		if _, exists := node[last]; !ok || exists {
+			conflicts = append(conflicts, key)
+			continue
+		}
+
+		node[last] = leaves[key]
+	}
+
+	return tree, conflicts
+}
+
+// 将字符串形式的数字及布尔值转换为对应的类型
+func typedValue(val interface{}) interface{} {
+	switch v := val.(type) {
+	case map[string]interface{}:
+		mp := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			mp[k] = typedValue(item)
+		}
+
+		return mp
+	case []map[string]interface{}:
+		arr := make([]interface{}, len(v))
+		for i, item := range v {
+			arr[i] = typedValue(item)
+		}
+
+		return arr
+	case []interface{}:
+		arr := make([]interface{}, len(v))
+		for i, item := range v {
+			arr[i] = typedValue(item)
+		}
+
+		return arr
+	case string:
+		if v == "true" || v == "false" {
+			return v == "true"
+		}
+
+		// 只转换格式规范的数字，如 0012 保持字符串
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil && strconv.FormatInt(n, 10) == v {
+			return n
+		}
+
+		if f, err := strconv.ParseFloat(v, 64); err == nil && strings.Contains(v, ".") && strconv.FormatFloat(f, 'f', -1, 64) == v {
+			return f
+		}
+	}
+
+	return val
+}
+
+/**
+ * 加载json内容，与yml及toml一样所有节点都在默认节
+ * @param data []byte json内容
+ * @return *Goini, error
+ */
+func loadJSON(data []byte) (*Goini, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var tree map[string]interface{}
+	if err := decoder.Decode(&tree); err != nil {
+		return nil, err
+	}
+
+	if tree == nil {
+		return nil, errors.New("goini: json content must be an object")
+	}
+
+	config := newGoini("", "")
+	config.Syntax = "json"
+	config.sections[defaultName] = jsonValue(tree)
+
+	return config, nil
+}
+
+// json的值转换为字符串，与其他格式解析后的值一致
+func jsonValue(val interface{}) interface{} {
+	switch v := val.(type) {
+	case map[string]interface{}:
+		for k, item := range v {
+			v[k] = jsonValue(item)
+		}
+
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = jsonValue(item)
+		}
+
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	case nil:
+		return ""
+	}
+
+	return val
+}
+
+// 节名，默认节在最前面，其他节按名称排序
+func sectionNames(sections map[string]map[string]interface{}) []string {
+	names := make([]string, 0, len(sections))
+	for name := range sections {
+		if name != defaultName {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return append([]string{defaultName}, names...)
+}
+
+// 排序后的key
+func sortedKeys(mp map[string]interface{}) []string {
+	keys := make([]string, 0, len(mp))
+	for key := range mp {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
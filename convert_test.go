@@ -0,0 +1,130 @@
+package goini
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	source := "env = prod\n[db]\nhost = 127.0.0.1\nport = 3306\nzip = 0012\nhosts = [a, b]\n[replica:db]\nhost = 10.0.0.2\ndsn = ${replica:host}:${db:port}\n"
+
+	testCases := []struct {
+		To     string
+		Expect string
+	}{
+		{To: "toml", Expect: "env = \"prod\"\n\n[db]\nhost = \"127.0.0.1\"\nhosts = [\"a\", \"b\"]\nport = 3306\nzip = \"0012\"\n\n[replica]\ndsn = \"10.0.0.2:3306\"\nhost = \"10.0.0.2\"\nhosts = [\"a\", \"b\"]\nport = 3306\nzip = \"0012\"\n"},
+		{To: "yml", Expect: "db:\n  host: 127.0.0.1\n  hosts: [a, b]\n  port: 3306\n  zip: 0012\nenv: prod\nreplica:\n  dsn: \"10.0.0.2:3306\"\n  host: 10.0.0.2\n  hosts: [a, b]\n  port: 3306\n  zip: 0012\n"},
+	}
+
+	for _, v := range testCases {
+		ret, warnings, err := Convert([]byte(source), "ini", v.To)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(ret) != v.Expect {
+			t.Errorf("Goini: Not as expected ret=%q, expect=%q", ret, v.Expect)
+		}
+
+		if len(warnings) != 0 {
+			t.Errorf("Goini: Not as expected warnings=%v", warnings)
+		}
+	}
+
+	ret, _, err := Convert([]byte(source), "ini", "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	back, _, err := Convert(ret, "", "ini")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := LoadBytes(back, "ini")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ret := config.GetString("dsn", "replica"); ret != "10.0.0.2:3306" {
+		t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, "10.0.0.2:3306")
+	}
+
+	if ret := config.GetInt("port", "db"); ret != 3306 {
+		t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, 3306)
+	}
+}
+
+func TestConvert_Warning(t *testing.T) {
+	source := "title = \"goini\"\n[[servers]]\nname = \"a\"\n"
+
+	ret, warnings, err := Convert([]byte(source), "toml", "ini")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(ret) != "title = goini\n" {
+		t.Errorf("Goini: Not as expected ret=%q, expect=%q", ret, "title = goini\n")
+	}
+
+	if len(warnings) != 1 || warnings[0].Key != "servers" {
+		t.Errorf("Goini: Not as expected warnings=%v", warnings)
+	}
+
+}
+
+func TestConvert_RoundTrip(t *testing.T) {
+	values := map[string]string{
+		"multi":   "line1\nline2",
+		"path":    `C:\dir\new`,
+		"quotes":  `say "hi", it's`,
+		"comment": "a # b; c",
+		"space":   " lead and trail ",
+		"mixed":   "tab\there \"q\" \\ end\r\n",
+	}
+
+	source, _ := json.Marshal(map[string]interface{}{"app": values})
+
+	for _, syntax := range []string{"ini", "yml", "toml"} {
+		ret, warnings, err := Convert(source, "json", syntax)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// ini的引号中不支持转义，无法写入换行及同时含有两种引号的值
+		skipped := make(map[string]bool)
+		for _, v := range warnings {
+			skipped[v.Key] = true
+		}
+
+		if syntax == "ini" && (len(warnings) != 3 || !skipped["multi"] || !skipped["quotes"] || !skipped["mixed"]) {
+			t.Errorf("Goini: Not as expected warnings=%v", warnings)
+		}
+
+		if syntax != "ini" && len(warnings) > 0 {
+			t.Errorf("Goini: Not as expected warnings=%v", warnings)
+		}
+
+		config, err := LoadBytes(ret, syntax)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for key, expect := range values {
+			if skipped[key] {
+				continue
+			}
+
+			var got string
+			if syntax == "ini" {
+				got = config.GetString(key, "app")
+			} else {
+				got = config.GetString("app." + key)
+			}
+
+			if got != expect {
+				t.Errorf("Goini: Not as expected syntax=%v ret=%q, expect=%q\n%s", syntax, got, expect, ret)
+			}
+		}
+	}
+}
@@ -1,15 +1,27 @@
 package goini
 
 import (
+	"encoding"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"os"
 	"reflect"
 	"strconv"
 	"strings"
 	"time"
 )
 
-func mapToStruct(key string, srcData map[string]interface{}, targetObj interface{}) error {
+// 时间字段默认的格式，可以通过 ini:"tpl=..." 标签修改
+const timeLayout = "2006-01-02 15:04:05"
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	timeType            = reflect.TypeOf(time.Time{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+func (goini *Goini) mapToStruct(key string, srcData map[string]interface{}, targetObj interface{}) error {
 	objV := reflect.ValueOf(targetObj)
 	objT := reflect.TypeOf(targetObj)
 
@@ -24,6 +36,9 @@ func mapToStruct(key string, srcData map[string]interface{}, targetObj interface
 		return errors.New("goini: The target are not struct")
 	}
 
+	// 第一个转换失败的字段，其他字段继续解析
+	var decodeErr error
+
 	for i := 0; i < objT.NumField(); i++ {
 		if !objV.Field(i).CanSet() {
 			continue
@@ -44,6 +59,8 @@ func mapToStruct(key string, srcData map[string]interface{}, targetObj interface
 			mapKey = tag
 		}
 
+		fieldName := mapKey
+
 		mapVal, ok := srcData[mapKey]
 		if !ok {
 			mapKey = key + "." + mapKey
@@ -57,18 +74,23 @@ func mapToStruct(key string, srcData map[string]interface{}, targetObj interface
 			k = t.Elem().Kind()
 		}
 
-		if t.String() == "time.Time" {
-			tpl := "2006-01-02 15:04:05"
+		// time.Time 及 *time.Time 按 tpl 标签的格式解析
+		if t == timeType || (tk == reflect.Ptr && t.Elem() == timeType) {
+			tpl := timeLayout
 			tplName, tplVal := parseTag(field.Tag.Get("ini"), "=")
 			if tplName == "tpl" && tplVal != "" {
 				tpl = string(tplVal)
 			}
 
 			if setVal, ok := mapVal.(string); ok {
-				loc, _ := time.LoadLocation("Local") //获取时区
-				theTime, _ := time.ParseInLocation(tpl, setVal, loc)
+				theTime, _ := parseTime(setVal, tpl)
 
 				tempV := reflect.ValueOf(theTime)
+				if tk == reflect.Ptr {
+					tempV = reflect.New(timeType)
+					tempV.Elem().Set(reflect.ValueOf(theTime))
+				}
+
 				objV.Field(i).Set(tempV)
 			}
 
@@ -78,7 +100,7 @@ func mapToStruct(key string, srcData map[string]interface{}, targetObj interface
 		if t.String() == "json.RawMessage" {
 			if setVal, ok := mapVal.(string); ok {
 
-				setVal = decodeVariable(setVal)
+				setVal = goini.decodeVariable(setVal)
 
 				tempV := reflect.ValueOf(json.RawMessage(setVal))
 				objV.Field(i).Set(tempV)
@@ -87,7 +109,12 @@ func mapToStruct(key string, srcData map[string]interface{}, targetObj interface
 			continue
 		}
 
-		if kv := decodeValue(mapVal, t); kv.IsValid() {
+		kv, err := goini.decodeValue(mapVal, t)
+		if err != nil {
+			decodeErr = fieldError(decodeErr, err, fieldName)
+		}
+
+		if kv.IsValid() {
 			if tk == reflect.Ptr {
 				// 初始化指针
 				ptrKv := reflect.New(kv.Type())
@@ -110,8 +137,10 @@ func mapToStruct(key string, srcData map[string]interface{}, targetObj interface
 				seq = string(arrSeq)
 			}
 
-			setVal, err := parseSlice(mapVal, field.Type, seq)
-			if err != nil {
+			setVal, err := goini.parseSlice(mapVal, field.Type, seq)
+			if isValueError(err) {
+				decodeErr = fieldError(decodeErr, err, fieldName)
+			} else if err != nil {
 				break
 			}
 
@@ -120,8 +149,10 @@ func mapToStruct(key string, srcData map[string]interface{}, targetObj interface
 			// todo
 			break
 		case reflect.Map:
-			setVal, err := parseMap(mapVal, field.Type)
-			if err != nil {
+			setVal, err := goini.parseMap(mapVal, field.Type)
+			if isValueError(err) {
+				decodeErr = fieldError(decodeErr, err, fieldName)
+			} else if err != nil {
 				break
 			}
 
@@ -150,68 +181,136 @@ func mapToStruct(key string, srcData map[string]interface{}, targetObj interface
 				}
 
 				if nextMap, ok := nextData.(map[string]interface{}); ok {
-					mapToStruct(mapKey, nextMap, val)
+					if err := goini.mapToStruct(mapKey, nextMap, val); isValueError(err) {
+						decodeErr = fieldError(decodeErr, err, fieldName)
+					}
 				}
 			}
 		}
 	}
 
-	return nil
+	return decodeErr
 }
 
-func parseInt(val interface{}) (int64, error) {
-	if valStr, ok := val.(string); ok {
-
-		valStr = decodeVariable(valStr)
+// 记录第一个转换失败的字段，节点名加上字段名前缀
+func fieldError(first, err error, name string) error {
+	if first != nil {
+		return first
+	}
 
-		if floatVal, err := strconv.ParseFloat(valStr, 64); err == nil {
-			return int64(floatVal), nil
+	var valueErr *ValueError
+	if errors.As(err, &valueErr) {
+		if valueErr.Key == "" {
+			valueErr.Key = name
 		} else {
-			return 0, err
+			valueErr.Key = name + "." + valueErr.Key
 		}
 	}
 
+	return err
+}
+
+// 是否为值转换失败的错误
+func isValueError(err error) bool {
+	var valueErr *ValueError
+
+	return errors.As(err, &valueErr)
+}
+
+func (goini *Goini) parseInt(val interface{}, bitSize int) (int64, error) {
+	if valStr, ok := val.(string); ok {
+
+		valStr = goini.decodeVariable(valStr)
+
+		return parseIntString(valStr, bitSize)
+	}
+
 	return 0, errors.New("goini: string assert error")
 }
 
-func parseUint(val interface{}) (uint64, error) {
+func (goini *Goini) parseUint(val interface{}, bitSize int) (uint64, error) {
 	if valStr, ok := val.(string); ok {
 
-		valStr = decodeVariable(valStr)
+		valStr = goini.decodeVariable(valStr)
 
-		if floatVal, err := strconv.ParseFloat(valStr, 64); err == nil {
-			return uint64(floatVal), nil
-		} else {
-			return 0, err
-		}
+		return parseUintString(valStr, bitSize)
 	}
 
 	return 0, errors.New("goini: string assert error")
 }
 
-func parseFloat(val interface{}) (float64, error) {
+func (goini *Goini) parseFloat(val interface{}, bitSize int) (float64, error) {
 	if valStr, ok := val.(string); ok {
 
-		valStr = decodeVariable(valStr)
+		valStr = goini.decodeVariable(valStr)
 
-		if floatVal, err := strconv.ParseFloat(valStr, 64); err == nil {
-			return floatVal, nil
-		} else {
-			return 0, err
-		}
+		return strconv.ParseFloat(strings.TrimSpace(valStr), bitSize)
 	}
 
 	return 0, errors.New("goini: string assert error")
 }
 
+/**
+ * 精确解析整数，支持 0x、0o、0b 前缀及 1_000_000 形式的分隔符，小数及超出范围的值返回错误
+ * 以0开头的十进制数如 0012 按十进制解析
+ * @param valStr string 整数字符串
+ * @param bitSize int 位数，超出范围时返回 strconv.ErrRange
+ * @return int64, error
+ */
+func parseIntString(valStr string, bitSize int) (int64, error) {
+	valStr = strings.TrimSpace(valStr)
+
+	return strconv.ParseInt(valStr, intBase(valStr), bitSize)
+}
+
+/**
+ * 精确解析无符号整数，规则与 parseIntString 相同
+ * @param valStr string 整数字符串
+ * @param bitSize int 位数，超出范围时返回 strconv.ErrRange
+ * @return uint64, error
+ */
+func parseUintString(valStr string, bitSize int) (uint64, error) {
+	valStr = strings.TrimSpace(valStr)
+
+	return strconv.ParseUint(strings.TrimPrefix(valStr, "+"), intBase(valStr), bitSize)
+}
+
+// 整数的进制，以0开头的十进制数按十进制解析，其他由前缀决定
+func intBase(valStr string) int {
+	digits := strings.TrimLeft(valStr, "+-")
+	if len(digits) > 1 && digits[0] == '0' && digits[1] >= '0' && digits[1] <= '9' {
+		return 10
+	}
+
+	return 0
+}
+
+func (goini *Goini) parseDuration(val interface{}) (time.Duration, error) {
+	if valStr, ok := val.(string); ok {
+
+		valStr = goini.decodeVariable(valStr)
+
+		return time.ParseDuration(strings.TrimSpace(valStr))
+	}
+
+	return 0, errors.New("goini: string assert error")
+}
+
+// 按本地时区解析时间
+func parseTime(valStr, tpl string) (time.Time, error) {
+	loc, _ := time.LoadLocation("Local") //获取时区
+
+	return time.ParseInLocation(tpl, strings.TrimSpace(valStr), loc)
+}
+
 func parseInterface(val interface{}) interface{} {
 	return val
 }
 
-func parseBool(val interface{}) (bool, error) {
+func (goini *Goini) parseBool(val interface{}) (bool, error) {
 	if valStr, ok := val.(string); ok {
 
-		valStr = decodeVariable(valStr)
+		valStr = goini.decodeVariable(valStr)
 
 		// 补充一些常用的词
 		switch valStr {
@@ -230,25 +329,26 @@ func parseBool(val interface{}) (bool, error) {
 }
 
 // 解析切片
-func parseSlice(val interface{}, t reflect.Type, delimiter string) (reflect.Value, error) {
+func (goini *Goini) parseSlice(val interface{}, t reflect.Type, delimiter string) (reflect.Value, error) {
 	if valStr, ok := val.(string); ok {
-		return parseStringToSlice(valStr, t, delimiter)
-	} else {
-		return parseSliceSlice(val, t)
+		return goini.parseStringToSlice(valStr, t, delimiter)
 	}
 
-	return reflect.MakeSlice(t, 0, 0), errors.New("goini: string assert error")
+	return goini.parseSliceSlice(val, t)
 }
 
 // 字符串解析为切片
-func parseStringToSlice(valStr string, t reflect.Type, delimiter string) (reflect.Value, error) {
-	valStr = decodeVariable(valStr)
+func (goini *Goini) parseStringToSlice(valStr string, t reflect.Type, delimiter string) (reflect.Value, error) {
+	valStr = goini.decodeVariable(valStr)
 
 	strArr := strings.Split(valStr, delimiter)
 	iL := len(strArr)
 	// 初始化切片
 	arr := reflect.MakeSlice(t, iL, iL)
 
+	// 第一个转换失败的元素
+	var decodeErr error
+
 	if iL > 0 {
 		var indexT reflect.Type
 		for i := 0; i < arr.Len(); i++ {
@@ -257,7 +357,12 @@ func parseStringToSlice(valStr string, t reflect.Type, delimiter string) (reflec
 			}
 
 			// 根据具体的类型设置对应的值
-			if kv := decodeValue(strArr[i], indexT); kv.IsValid() {
+			kv, err := goini.decodeValue(strArr[i], indexT)
+			if err != nil && decodeErr == nil {
+				decodeErr = err
+			}
+
+			if kv.IsValid() {
 				if indexT.Kind() == reflect.Ptr {
 					// 初始化指针
 					ptrKv := reflect.New(kv.Type())
@@ -271,11 +376,11 @@ func parseStringToSlice(valStr string, t reflect.Type, delimiter string) (reflec
 		}
 	}
 
-	return arr, nil
+	return arr, decodeErr
 }
 
 // 解析map格式的切片
-func parseSliceSlice(val interface{}, t reflect.Type) (reflect.Value, error) {
+func (goini *Goini) parseSliceSlice(val interface{}, t reflect.Type) (reflect.Value, error) {
 	if arrVal, ok := val.([]interface{}); ok {
 		iL := len(arrVal)
 
@@ -285,6 +390,9 @@ func parseSliceSlice(val interface{}, t reflect.Type) (reflect.Value, error) {
 
 		arr := reflect.MakeSlice(t, iL, iL)
 
+		// 第一个转换失败的元素
+		var decodeErr error
+
 		var indexT reflect.Type
 		for i := 0; i < arr.Len(); i++ {
 			if indexT == nil {
@@ -297,7 +405,9 @@ func parseSliceSlice(val interface{}, t reflect.Type) (reflect.Value, error) {
 					valTemp := reflect.New(indexT.Elem())
 					nextVal := valTemp.Interface()
 
-					mapToStruct("", arrValMap, nextVal)
+					if err := goini.mapToStruct("", arrValMap, nextVal); isValueError(err) {
+						decodeErr = fieldError(decodeErr, err, strconv.Itoa(i))
+					}
 
 					arr.Index(i).Set(valTemp)
 
@@ -305,12 +415,20 @@ func parseSliceSlice(val interface{}, t reflect.Type) (reflect.Value, error) {
 					valTemp := reflect.New(indexT)
 					nextVal := valTemp.Interface()
 
-					mapToStruct("", arrValMap, nextVal)
+					if err := goini.mapToStruct("", arrValMap, nextVal); isValueError(err) {
+						decodeErr = fieldError(decodeErr, err, strconv.Itoa(i))
+					}
+
 					arr.Index(i).Set(valTemp.Elem())
 				}
 			} else if strVal, ok := tempObj.(string); ok {
 				// 根据具体的类型设置对应的值
-				if kv := decodeValue(strVal, indexT); kv.IsValid() {
+				kv, err := goini.decodeValue(strVal, indexT)
+				if err != nil && decodeErr == nil {
+					decodeErr = err
+				}
+
+				if kv.IsValid() {
 					if indexT.Kind() == reflect.Ptr {
 						// 初始化指针
 						ptrKv := reflect.New(kv.Type())
@@ -322,8 +440,10 @@ func parseSliceSlice(val interface{}, t reflect.Type) (reflect.Value, error) {
 					}
 				}
 			} else if arrVal, ok := tempObj.([]interface{}); ok {
-				retSlice, err := parseSliceSlice(arrVal, indexT)
-				if err != nil {
+				retSlice, err := goini.parseSliceSlice(arrVal, indexT)
+				if isValueError(err) {
+					decodeErr = fieldError(decodeErr, err, strconv.Itoa(i))
+				} else if err != nil {
 					panic(err.Error())
 				}
 
@@ -331,22 +451,30 @@ func parseSliceSlice(val interface{}, t reflect.Type) (reflect.Value, error) {
 			}
 		}
 
-		return arr, nil
+		return arr, decodeErr
 	}
 
 	return reflect.MakeSlice(t, 0, 0), errors.New("goini: parseSliceSlice slice assert error")
 }
 
-func parseMap(val interface{}, t reflect.Type) (reflect.Value, error) {
+func (goini *Goini) parseMap(val interface{}, t reflect.Type) (reflect.Value, error) {
 	m := reflect.MakeMap(t)
 
+	// 第一个转换失败的元素
+	var decodeErr error
+
 	if valMap, valMapOk := val.(map[string]interface{}); valMapOk {
 		for k, v := range valMap {
 			if vStr, ok := v.(string); ok {
 
-				vStr = decodeVariable(vStr)
+				vStr = goini.decodeVariable(vStr)
 
-				if kv := decodeValue(vStr, t.Elem()); kv.IsValid() {
+				kv, err := goini.decodeValue(vStr, t.Elem())
+				if err != nil && decodeErr == nil {
+					decodeErr = fieldError(decodeErr, err, k)
+				}
+
+				if kv.IsValid() {
 					if t.Elem().Kind() == reflect.Ptr {
 						// 初始化指针
 						ptrKv := reflect.New(kv.Type())
@@ -362,27 +490,72 @@ func parseMap(val interface{}, t reflect.Type) (reflect.Value, error) {
 		}
 	}
 
-	return m, nil
+	return m, decodeErr
 }
 
-func decodeValue(v interface{}, t reflect.Type) reflect.Value {
+func (goini *Goini) decodeValue(v interface{}, t reflect.Type) (reflect.Value, error) {
 	var kv reflect.Value
+	var decodeErr error
 
 	if v == nil {
-		return kv
+		return kv, nil
 	}
 
 	// 检查具体的类型
-	k := t.Kind()
-	if k == reflect.Ptr {
-		k = t.Elem().Kind()
+	elemT := t
+	if elemT.Kind() == reflect.Ptr {
+		elemT = t.Elem()
+	}
+
+	k := elemT.Kind()
+
+	// time.Duration 使用 5s、1h30m 等格式
+	if elemT == durationType {
+		setVal, err := goini.parseDuration(v)
+		if err != nil {
+			decodeErr = goini.decodeError(v, elemT, err)
+		}
+
+		return reflect.ValueOf(setVal).Convert(elemT), decodeErr
+	}
+
+	// time.Time 虽然实现了 encoding.TextUnmarshaler，仍使用默认的时间格式，与结构体字段一致
+	if elemT == timeType {
+		valStr, ok := v.(string)
+		if !ok {
+			return reflect.Zero(elemT), goini.decodeError(v, elemT, nil)
+		}
+
+		setVal, err := parseTime(goini.decodeVariable(valStr), timeLayout)
+		if err != nil {
+			decodeErr = goini.decodeError(v, elemT, err)
+		}
+
+		return reflect.ValueOf(setVal), decodeErr
+	}
+
+	// 实现 encoding.TextUnmarshaler 的类型，如 ByteSize
+	if reflect.PtrTo(elemT).Implements(textUnmarshalerType) {
+		ptrVal := reflect.New(elemT)
+
+		valStr, ok := v.(string)
+		if !ok {
+			return ptrVal.Elem(), goini.decodeError(v, elemT, nil)
+		}
+
+		if err := ptrVal.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(goini.decodeVariable(valStr))); err != nil {
+			return reflect.Zero(elemT), goini.decodeError(v, elemT, err)
+		}
+
+		return ptrVal.Elem(), nil
 	}
 
 	switch k {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		setVal, err := parseInt(v)
+		setVal, err := goini.parseInt(v, elemT.Bits())
 		if err != nil {
 			setVal = 0
+			decodeErr = goini.decodeError(v, elemT, err)
 		}
 
 		if t.Kind() == reflect.Ptr {
@@ -391,9 +564,10 @@ func decodeValue(v interface{}, t reflect.Type) reflect.Value {
 			kv = reflect.ValueOf(setVal).Convert(t)
 		}
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
-		setVal, err := parseUint(v)
+		setVal, err := goini.parseUint(v, elemT.Bits())
 		if err != nil {
 			setVal = 0
+			decodeErr = goini.decodeError(v, elemT, err)
 		}
 
 		if t.Kind() == reflect.Ptr {
@@ -402,9 +576,10 @@ func decodeValue(v interface{}, t reflect.Type) reflect.Value {
 			kv = reflect.ValueOf(setVal).Convert(t)
 		}
 	case reflect.Float32, reflect.Float64:
-		setVal, err := parseFloat(v)
+		setVal, err := goini.parseFloat(v, elemT.Bits())
 		if err != nil {
 			setVal = 0
+			decodeErr = goini.decodeError(v, elemT, err)
 		}
 
 		if t.Kind() == reflect.Ptr {
@@ -415,7 +590,7 @@ func decodeValue(v interface{}, t reflect.Type) reflect.Value {
 	case reflect.String:
 		if setVal, ok := v.(string); ok {
 
-			setVal = decodeVariable(setVal)
+			setVal = goini.decodeVariable(setVal)
 
 			if t.Kind() == reflect.Ptr {
 				kv = reflect.ValueOf(&setVal).Elem().Convert(t.Elem())
@@ -432,9 +607,10 @@ func decodeValue(v interface{}, t reflect.Type) reflect.Value {
 			kv = reflect.ValueOf(setVal).Convert(t)
 		}
 	case reflect.Bool:
-		setVal, err := parseBool(v)
+		setVal, err := goini.parseBool(v)
 		if err != nil {
 			setVal = false
+			decodeErr = goini.decodeError(v, elemT, nil)
 		}
 
 		if t.Kind() == reflect.Ptr {
@@ -444,14 +620,27 @@ func decodeValue(v interface{}, t reflect.Type) reflect.Value {
 		}
 	default:
 		//其他类型暂时不处理
-		return kv
+		return kv, nil
 	}
 
-	return kv
+	return kv, decodeErr
+}
+
+// 值转换失败的错误，节点名及节名由调用方补充
+func (goini *Goini) decodeError(v interface{}, t reflect.Type, err error) error {
+	valStr, ok := v.(string)
+	if ok {
+		valStr = goini.decodeVariable(valStr)
+	} else {
+		valStr = formatChangeValue(v)
+		err = nil
+	}
+
+	return &ValueError{Value: valStr, Type: t.String(), Err: numError(err)}
 }
 
 // 解析变量, 格式：${section:name1.name2}
-func decodeVariable(dest string) string {
+func (goini *Goini) decodeVariable(dest string) string {
 	varArr := rxVariate.FindAllString(dest, -1)
 	if len(varArr) == 0 {
 		return dest
@@ -459,20 +648,17 @@ func decodeVariable(dest string) string {
 
 	for _, v := range varArr {
 		if len(v) >= 3 {
-			varName := v[2 : len(v)-1]
+			varName, modifier, hasModifier := splitVariable(v[2 : len(v)-1])
 
-			varVal := ""
-			posVal := strings.IndexAny(varName, ":")
+			varVal, ok := goini.lookupVariable(varName)
 
-			if posVal != -1 {
-				// 获取含有section的数据
-				varVal = getString(varName[posVal+1:], varName[:posVal])
-			} else {
-				varVal = getString(varName, "")
+			// 未取到值时使用默认值，必填的变量保留原样
+			if !ok && hasModifier && !strings.HasPrefix(modifier, "?") {
+				varVal, ok = modifier, true
 			}
 
 			// 替换为变量的值
-			if varVal != "" {
+			if ok {
 				// go version < 1.11 不支持string.ReplaceAll()
 				dest = strings.Replace(dest, v, varVal, -1)
 			}
@@ -482,13 +668,174 @@ func decodeVariable(dest string) string {
 	return dest
 }
 
+// 获取变量的值，变量为空时视为未找到
+func (goini *Goini) lookupVariable(varName string) (string, bool) {
+	posVal := strings.IndexAny(varName, ":")
+	if posVal == -1 {
+		varVal := goini.getString(varName, "")
+		return varVal, varVal != ""
+	}
+
+	// 获取含有section的数据
+	if varVal := goini.getString(varName[posVal+1:], varName[:posVal]); varVal != "" {
+		return varVal, true
+	}
+
+	// ${env:NAME} 读取环境变量，存在 [env] 节时以节中的值优先
+	if varName[:posVal] == envNamespace {
+		return goini.lookupEnv(varName[posVal+1:])
+	}
+
+	return "", false
+}
+
+// 拆分变量中的修饰符，${key|default} 为默认值，${key|?message} 为必填
+func splitVariable(varName string) (string, string, bool) {
+	if pos := strings.Index(varName, "|"); pos != -1 {
+		return varName[:pos], varName[pos+1:], true
+	}
+
+	return varName, "", false
+}
+
+// 必填变量及其所在的位置
+type requiredVar struct {
+	file    string
+	line    int
+	column  int
+	text    string
+	raw     string
+	name    string
+	message string
+}
+
+// 记录值中的必填变量
+func (goini *Goini) recordRequired(valStr string) {
+	for _, v := range rxVariate.FindAllString(valStr, -1) {
+		if len(v) < 3 {
+			continue
+		}
+
+		varName, modifier, hasModifier := splitVariable(v[2 : len(v)-1])
+		if !hasModifier || !strings.HasPrefix(modifier, "?") {
+			continue
+		}
+
+		goini.required = append(goini.required, requiredVar{
+			file:    goini.parseName,
+			line:    goini.lineNum,
+			column:  strings.Index(goini.lineText, v) + 1,
+			text:    goini.lineText,
+			raw:     v,
+			name:    varName,
+			message: modifier[1:],
+		})
+	}
+}
+
+// 检查必填变量，未找到时返回 *ParseError
+func (goini *Goini) checkRequired() error {
+	for _, v := range goini.required {
+		if goini.variableExists(v.name, v.raw) {
+			continue
+		}
+
+		err := fmt.Errorf("%w: ${%s}", ErrMissingVariable, v.name)
+		if v.message != "" {
+			err = fmt.Errorf("%w: ${%s}: %s", ErrMissingVariable, v.name, v.message)
+		}
+
+		column := v.column
+		if column < 1 {
+			column = 1
+		}
+
+		return &ParseError{
+			File:   v.file,
+			Line:   v.line,
+			Column: column,
+			Text:   v.text,
+			Err:    err,
+		}
+	}
+
+	return nil
+}
+
+// 变量是否存在，值可以是map等非字符串类型，仍含有该变量本身的值视为不存在
+func (goini *Goini) variableExists(varName, varStr string) bool {
+	if varVal, ok := goini.lookupVariable(varName); ok {
+		return !strings.Contains(varVal, varStr)
+	}
+
+	section := ""
+	if posVal := strings.IndexAny(varName, ":"); posVal != -1 {
+		section, varName = varName[:posVal], varName[posVal+1:]
+	}
+
+	val := goini.getValBySection(varName, section)
+
+	return val != nil && val != ""
+}
+
+// 替换值中的变量，map及切片会递归处理并返回副本
+func (goini *Goini) resolveValue(val interface{}) interface{} {
+	switch v := val.(type) {
+	case string:
+		return goini.decodeVariable(v)
+	case map[string]interface{}:
+		mp := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			mp[k] = goini.resolveValue(item)
+		}
+
+		return mp
+	case []map[string]interface{}:
+		arr := make([]map[string]interface{}, len(v))
+		for i, item := range v {
+			arr[i], _ = goini.resolveValue(item).(map[string]interface{})
+		}
+
+		return arr
+	case []interface{}:
+		arr := make([]interface{}, len(v))
+		for i, item := range v {
+			arr[i] = goini.resolveValue(item)
+		}
+
+		return arr
+	}
+
+	return val
+}
+
+// 获取环境变量，未允许读取或不存在时返回false
+func (goini *Goini) lookupEnv(name string) (string, bool) {
+	if goini.envAllow == nil || !goini.envAllow(name) {
+		return "", false
+	}
+
+	return os.LookupEnv(name)
+}
+
+// 是否是环境变量引用，存在 [env] 节时按节解析
+func (goini *Goini) isEnvVariate(varName string) bool {
+	if !strings.HasPrefix(varName, envNamespace+":") {
+		return false
+	}
+
+	_, ok := goini.sections[envNamespace]
+
+	return !ok
+}
+
 // 获取变量的值
-func getString(key, section string) string {
+func (goini *Goini) getString(key, section string) string {
 	if section == "" || section == "<nil>" {
 		section = defaultName
 	}
 
-	if tempRet, ok := sections[section].(map[string]interface{}); ok {
+	if tempRet, ok := goini.sections[section].(map[string]interface{}); ok {
 		if val, vOk := tempRet[key]; vOk {
 			if valStr, strOk := val.(string); strOk {
 				return valStr
@@ -0,0 +1,121 @@
+package goini
+
+import (
+	"bufio"
+	"bytes"
+	"path/filepath"
+	"strings"
+)
+
+/**
+ * 推断文件格式，优先根据扩展名判断，无法判断时根据内容判断
+ * @param name string 文件名，可以为空
+ * @param content []byte 文件内容
+ * @return string 文件格式 ini、yml 或 toml
+ */
+func DetectSyntax(name string, content []byte) string {
+	if syntax := syntaxByExt(name); syntax != "" {
+		return syntax
+	}
+
+	return sniffSyntax(content)
+}
+
+// 根据扩展名获取文件格式，未知扩展名返回空
+func syntaxByExt(name string) string {
+	ext := strings.TrimPrefix(filepath.Ext(name), ".")
+	if ext == "" {
+		return ""
+	}
+
+	if syntax, ok := syntaxMap[ext]; ok {
+		return syntax
+	}
+
+	return syntaxMap[strings.ToLower(ext)]
+}
+
+// 根据内容判断文件格式
+func sniffSyntax(content []byte) string {
+	var yamlNum, tomlNum, iniNum, quoteNum int
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		trimStr := strings.TrimSpace(scanner.Text())
+		if trimStr == "" || strings.HasPrefix(trimStr, "#") || strings.HasPrefix(trimStr, ";") {
+			continue
+		}
+
+		// toml 数组表格
+		if rxTomlArrayTable.MatchString(trimStr) {
+			return "toml"
+		}
+
+		// 节或表名，ini 的继承写法只有 ini 支持
+		if strings.HasPrefix(trimStr, "[") {
+			if strings.Index(trimStr, ":") != -1 {
+				iniNum++
+			}
+
+			continue
+		}
+
+		posEq := strings.Index(trimStr, "=")
+		posColon := strings.Index(trimStr, ":")
+
+		// yaml 的 key: value 或 - item 形式
+		if rxYamlKey.MatchString(trimStr) && (posEq == -1 || posColon < posEq) {
+			yamlNum++
+			continue
+		}
+
+		if strings.HasPrefix(trimStr, "- ") {
+			yamlNum++
+			continue
+		}
+
+		if posEq > 0 {
+			valueStr := RemoveComments(strings.TrimSpace(trimStr[posEq+1:]))
+			if isTomlValue(valueStr) {
+				tomlNum++
+
+				if strings.HasPrefix(valueStr, "\"") || strings.HasPrefix(valueStr, "'") {
+					quoteNum++
+				}
+			} else {
+				iniNum++
+			}
+		}
+	}
+
+	if yamlNum > 0 && tomlNum == 0 && iniNum == 0 {
+		return "yml"
+	}
+
+	// 所有值都符合toml规范，并且至少有一个带引号的字符串
+	if tomlNum > 0 && iniNum == 0 && quoteNum > 0 {
+		return "toml"
+	}
+
+	return "ini"
+}
+
+// 是否是合法的toml值
+func isTomlValue(valueStr string) bool {
+	if valueStr == "" {
+		return false
+	}
+
+	switch valueStr[0] {
+	case '"', '\'':
+		return len(valueStr) >= 2 && valueStr[len(valueStr)-1] == valueStr[0]
+	case '[', '{':
+		return true
+	}
+
+	if valueStr == "true" || valueStr == "false" {
+		return true
+	}
+
+	return rxTomlNumber.MatchString(valueStr) || rxTomlDate.MatchString(valueStr)
+}
@@ -0,0 +1,56 @@
+package goini
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectSyntax(t *testing.T) {
+	testCases := []struct {
+		Name    string
+		Content string
+		Expect  string
+	}{
+		{Name: "app.yaml", Expect: "yml"},
+		{Name: "app.TOML", Expect: "toml"},
+		{Name: "app.conf", Expect: "ini"},
+		{Name: "app.cfg", Content: "[[servers]]\nname = \"a\"\n", Expect: "toml"},
+		{Name: "app.cfg", Content: "[db]\nhost = \"127.0.0.1\"\nport = 3306\n", Expect: "toml"},
+		{Name: "app.cfg", Content: "env = \"test\"\nhost = 127.0.0.1\n", Expect: "ini"},
+		{Name: "app.cfg", Content: "[redis:db]\nport = \"6379\"\n", Expect: "ini"},
+		{Name: "", Content: "# app\napp:\n  name: goini\n  hosts:\n    - a\n", Expect: "yml"},
+		{Name: "", Content: "", Expect: "ini"},
+	}
+
+	for _, v := range testCases {
+		if ret := DetectSyntax(v.Name, []byte(v.Content)); ret != v.Expect {
+			t.Errorf("Goini: Not as expected name=%v ret=%v, expect=%v", v.Name, ret, v.Expect)
+		}
+	}
+}
+
+func TestLoadFile_DetectSyntax(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "app.yaml")
+	ioutil.WriteFile(path, []byte("db:\n  port: 3306\n"), 0644)
+
+	config, err := LoadFile(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.Syntax != "yml" || config.GetInt("db.port") != 3306 {
+		t.Errorf("Goini: Not as expected syntax=%v, port=%v", config.Syntax, config.Get("db.port"))
+	}
+
+	config, err = LoadString("[db]\nport = 3306\n[[servers]]\nname = \"a\"\n", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.Syntax != "toml" || config.GetInt("db.port") != 3306 {
+		t.Errorf("Goini: Not as expected syntax=%v, port=%v", config.Syntax, config.Get("db.port"))
+	}
+}
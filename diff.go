@@ -0,0 +1,127 @@
+package goini
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// 变化类型
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// Change 节点值的变化，Old 或 New 在新增或删除时为nil
+type Change struct {
+	Section string
+	Key     string
+	Kind    string
+	Old     interface{}
+	New     interface{}
+}
+
+func (c Change) String() string {
+	key := c.Key
+	if c.Section != "" && c.Section != defaultName {
+		key = "[" + c.Section + "] " + c.Key
+	}
+
+	switch c.Kind {
+	case ChangeAdded:
+		return "+ " + key + " = " + formatChangeValue(c.New)
+	case ChangeRemoved:
+		return "- " + key + " = " + formatChangeValue(c.Old)
+	}
+
+	return "~ " + key + ": " + formatChangeValue(c.Old) + " -> " + formatChangeValue(c.New)
+}
+
+/**
+ * 比较两个配置解析后的值，继承的节及变量会先被解析，可以比较不同格式的配置
+ * yml、toml等格式第一层的表格与ini中同名的节对应
+ * @param a *Goini 旧配置
+ * @param b *Goini 新配置
+ * @return []Change 按节名及节点名排序，默认节在最前面
+ */
+func Diff(a, b *Goini) []Change {
+	oldSections := a.diffSections()
+	newSections := b.diffSections()
+
+	// 所有节名
+	all := make(map[string]map[string]interface{}, len(oldSections)+len(newSections))
+	for name := range oldSections {
+		all[name] = nil
+	}
+
+	for name := range newSections {
+		all[name] = nil
+	}
+
+	var changes []Change
+
+	for _, section := range sectionNames(all) {
+		oldLeaves, newLeaves := oldSections[section], newSections[section]
+
+		keys := make(map[string]interface{}, len(oldLeaves)+len(newLeaves))
+		for key := range oldLeaves {
+			keys[key] = nil
+		}
+
+		for key := range newLeaves {
+			keys[key] = nil
+		}
+
+		for _, key := range sortedKeys(keys) {
+			oldVal, oldOk := oldLeaves[key]
+			newVal, newOk := newLeaves[key]
+
+			switch {
+			case !oldOk:
+				changes = append(changes, Change{Section: section, Key: key, Kind: ChangeAdded, New: newVal})
+			case !newOk:
+				changes = append(changes, Change{Section: section, Key: key, Kind: ChangeRemoved, Old: oldVal})
+			case !reflect.DeepEqual(typedValue(oldVal), typedValue(newVal)):
+				changes = append(changes, Change{Section: section, Key: key, Kind: ChangeChanged, Old: oldVal, New: newVal})
+			}
+		}
+	}
+
+	return changes
+}
+
+// 获取每个节解析后的节点，嵌套的map展开为以.分隔的key
+func (goini *Goini) diffSections() map[string]map[string]interface{} {
+	goini.mu.RLock()
+	sections, _ := goini.resolvedSections()
+	goini.mu.RUnlock()
+
+	promoteSections(sections)
+
+	ret := make(map[string]map[string]interface{}, len(sections))
+	for name, property := range sections {
+		leaves := make(map[string]interface{})
+		flattenValue("", property, leaves)
+
+		if len(leaves) > 0 {
+			ret[name] = leaves
+		}
+	}
+
+	return ret
+}
+
+// 输出变化的值，字符串原样输出，其他类型输出为json
+func formatChangeValue(val interface{}) string {
+	if valStr, ok := val.(string); ok {
+		return valStr
+	}
+
+	data, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Sprintf("%v", val)
+	}
+
+	return string(data)
+}
@@ -0,0 +1,43 @@
+package goini
+
+import (
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	a, err := LoadString("env = prod\nname = goini\n[db]\nhost = 127.0.0.1\nport = 3306\n[replica:db]\nhost = ${db:host}\n", "ini")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := LoadString("env = \"prod\"\nversion = \"1.0\"\n\n[db]\nhost = \"127.0.0.1\"\nport = 3307\n\n[replica]\nhost = \"127.0.0.1\"\n", "toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []Change{
+		{Section: "default", Key: "name", Kind: ChangeRemoved, Old: "goini"},
+		{Section: "default", Key: "version", Kind: ChangeAdded, New: "1.0"},
+		{Section: "db", Key: "port", Kind: ChangeChanged, Old: "3306", New: "3307"},
+		{Section: "replica", Key: "port", Kind: ChangeRemoved, Old: "3306"},
+	}
+
+	changes := Diff(a, b)
+	if len(changes) != len(testCases) {
+		t.Fatalf("Goini: Not as expected ret=%v, expect=%v", changes, testCases)
+	}
+
+	for i, v := range testCases {
+		if changes[i] != v {
+			t.Errorf("Goini: Not as expected ret=%v, expect=%v", changes[i], v)
+		}
+	}
+
+	if ret := changes[2].String(); ret != "~ [db] port: 3306 -> 3307" {
+		t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, "~ [db] port: 3306 -> 3307")
+	}
+
+	if changes := Diff(a, a); len(changes) != 0 {
+		t.Errorf("Goini: Not as expected ret=%v", changes)
+	}
+}
@@ -0,0 +1,272 @@
+package goini
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// 行类型
+const (
+	lineBlank = iota
+	lineComment
+	lineSection
+	lineProperty
+	lineOther
+)
+
+// ini文件中的一行，保留原始内容
+type docLine struct {
+	kind int
+	raw  string
+
+	// 所属节名
+	section string
+
+	// 节点名及值在原始内容中的位置
+	key      string
+	valStart int
+	valEnd   int
+}
+
+// ini文件的语法树，保留注释、空行、引号及顺序，修改后写回时只改变对应的行
+type document struct {
+	lines   []*docLine
+	newline string
+
+	// 文件是否以换行结尾
+	endNewline bool
+
+	// 无法写入的值，保存时返回错误，再次设置为可以写入的值后删除
+	invalid map[string]error
+}
+
+/**
+ * 解析ini文件内容为语法树
+ * @param content []byte 文件内容
+ * @return *document
+ */
+func parseDocument(content []byte) *document {
+	doc := &document{
+		newline: "\n",
+	}
+
+	if bytes.Contains(content, []byte("\r\n")) {
+		doc.newline = "\r\n"
+	}
+
+	text := string(content)
+	if strings.HasSuffix(text, "\n") {
+		doc.endNewline = true
+		text = text[:len(text)-1]
+	}
+
+	if text == "" && !doc.endNewline {
+		doc.endNewline = true
+		return doc
+	}
+
+	section := defaultName
+	for _, raw := range strings.Split(text, "\n") {
+		raw = strings.TrimSuffix(raw, "\r")
+		line := &docLine{
+			kind: lineOther,
+			raw:  raw,
+		}
+
+		trimStr := strings.TrimSpace(raw)
+
+		if trimStr == "" {
+			line.kind = lineBlank
+		} else if strings.HasPrefix(trimStr, "#") || strings.HasPrefix(trimStr, ";") {
+			line.kind = lineComment
+		} else if rxFirstSection.MatchString(trimStr) {
+			line.kind = lineSection
+			section = docSectionName(rxFirstSection.FindString(trimStr))
+		} else if !rxInclude.MatchString(trimStr) && rxNode.MatchString(trimStr) {
+			line.kind = lineProperty
+			posEq := strings.Index(raw, "=")
+			line.key = parsNodeName(raw[:posEq])
+			line.valStart, line.valEnd = valueSpan(raw, posEq+1)
+		}
+
+		line.section = section
+		doc.lines = append(doc.lines, line)
+	}
+
+	return doc
+}
+
+// 获取节名，继承的节使用子节名，与 parseSection 一致
+func docSectionName(rowStr string) string {
+	sectionName := rxSection.ReplaceAllString(rowStr, "")
+
+	pos := strings.Index(sectionName, ":")
+	if pos != -1 {
+		child, parent := sectionName[:pos], sectionName[pos+1:]
+		if child != "" && child != parent {
+			return child
+		}
+	}
+
+	return sectionName
+}
+
+// 获取值在行中的位置，不包含前后空白及行尾注释
+func valueSpan(raw string, start int) (int, int) {
+	for start < len(raw) && (raw[start] == ' ' || raw[start] == '\t') {
+		start++
+	}
+
+	valStr := raw[start:]
+
+	// 引号中的值
+	if end := quotedLen(valStr); end != -1 {
+		return start, start + end
+	}
+
+	// 行尾注释，含有引号时与 parsNodeValue 一样不处理注释
+	if !strings.ContainsAny(valStr, "\"'") {
+		if pos := strings.IndexAny(valStr, "#;"); pos != -1 {
+			valStr = valStr[:pos]
+		}
+	}
+
+	return start, start + len(strings.TrimRight(valStr, " \t"))
+}
+
+/**
+ * 设置节点值，已存在的节点只替换值，否则追加到节的末尾，节不存在时在文件末尾新增节
+ * @param section string 节名
+ * @param key string 节点名
+ * @param valStr string 值，含有注释符号、首尾空白等内容时加上引号
+ */
+func (doc *document) set(section, key, valStr string) {
+	key = parsNodeName(key)
+
+	// 无法写为ini的值不修改语法树，保存时返回错误
+	name := "[" + section + "] " + key
+	if _, err := iniText(valStr); err != nil {
+		if doc.invalid == nil {
+			doc.invalid = make(map[string]error)
+		}
+
+		doc.invalid[name] = fmt.Errorf("goini: %s: %w", name, err)
+		return
+	}
+
+	delete(doc.invalid, name)
+
+	// 同名节点以最后一个为准
+	for i := len(doc.lines) - 1; i >= 0; i-- {
+		line := doc.lines[i]
+		if line.kind == lineProperty && line.section == section && line.key == key {
+			doc.replaceValue(line, valStr)
+			return
+		}
+	}
+
+	newLine := &docLine{
+		kind:    lineProperty,
+		section: section,
+		key:     key,
+	}
+
+	text, _ := iniText(valStr)
+
+	newLine.raw = key + " = " + text
+	newLine.valStart = len(key) + 3
+	newLine.valEnd = len(newLine.raw)
+
+	// 追加到节的最后一个节点之后
+	pos := -1
+	for i, line := range doc.lines {
+		if line.section != section {
+			continue
+		}
+
+		if line.kind == lineProperty || line.kind == lineSection || line.kind == lineOther {
+			pos = i + 1
+		}
+	}
+
+	if pos != -1 {
+		doc.insert(pos, newLine)
+		return
+	}
+
+	if section == defaultName {
+		// 默认节放在第一个节之前
+		for i, line := range doc.lines {
+			if line.kind == lineSection {
+				doc.insert(i, newLine, &docLine{kind: lineBlank, section: defaultName})
+				return
+			}
+		}
+
+		doc.lines = append(doc.lines, newLine)
+		return
+	}
+
+	if len(doc.lines) > 0 && doc.lines[len(doc.lines)-1].kind != lineBlank {
+		doc.lines = append(doc.lines, &docLine{kind: lineBlank, section: doc.lines[len(doc.lines)-1].section})
+	}
+
+	doc.lines = append(doc.lines, &docLine{kind: lineSection, raw: "[" + section + "]", section: section}, newLine)
+}
+
+// 替换值，原来使用引号且新值不含该引号时保持相同的引号，否则按需加上引号
+func (doc *document) replaceValue(line *docLine, valStr string) {
+	oldVal := line.raw[line.valStart:line.valEnd]
+
+	if len(oldVal) >= 2 && (oldVal[0] == '"' || oldVal[0] == '\'') &&
+		!strings.Contains(valStr, oldVal[:1]) && !strings.HasPrefix(valStr, "[") {
+		valStr = oldVal[:1] + valStr + oldVal[:1]
+	} else {
+		valStr, _ = iniText(valStr)
+	}
+
+	line.raw = line.raw[:line.valStart] + valStr + line.raw[line.valEnd:]
+	line.valEnd = line.valStart + len(valStr)
+}
+
+// 在指定位置插入行
+func (doc *document) insert(pos int, lines ...*docLine) {
+	doc.lines = append(doc.lines[:pos], append(lines, doc.lines[pos:]...)...)
+}
+
+// 无法写入的值，按节点名排序后返回第一个错误
+func (doc *document) err() error {
+	names := make([]string, 0, len(doc.invalid))
+	for name := range doc.invalid {
+		names = append(names, name)
+	}
+
+	if len(names) == 0 {
+		return nil
+	}
+
+	sort.Strings(names)
+
+	return doc.invalid[names[0]]
+}
+
+// 输出文件内容
+func (doc *document) bytes() *bytes.Buffer {
+	buf := &bytes.Buffer{}
+
+	for i, line := range doc.lines {
+		if i > 0 {
+			buf.WriteString(doc.newline)
+		}
+
+		buf.WriteString(line.raw)
+	}
+
+	if doc.endNewline && len(doc.lines) > 0 {
+		buf.WriteString(doc.newline)
+	}
+
+	return buf
+}
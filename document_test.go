@@ -0,0 +1,141 @@
+package goini
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteTo_Document(t *testing.T) {
+	source := "# app config\r\n" +
+		"env = 'test' ; current env\r\n" +
+		"\r\n" +
+		"[db]\r\n" +
+		"; connection\r\n" +
+		"host = \"127.0.0.1\"\r\n" +
+		"port   =   3306   # default port\r\n" +
+		"\r\n" +
+		"[redis:db]\r\n" +
+		"port = 6379\r\n"
+
+	config, err := LoadString(source, "ini")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	config.WriteTo(buf)
+	if buf.String() != source {
+		t.Errorf("Goini: Not as expected ret=%q, expect=%q", buf.String(), source)
+	}
+
+	config.Set("env", "prod")
+	config.Set("port", 3307, "db")
+	config.Set("host", "10.0.0.1", "redis")
+	config.Set("name", "goini")
+	config.Set("size", 10, "cache")
+
+	expect := "# app config\r\n" +
+		"env = 'prod' ; current env\r\n" +
+		"name = goini\r\n" +
+		"\r\n" +
+		"[db]\r\n" +
+		"; connection\r\n" +
+		"host = \"127.0.0.1\"\r\n" +
+		"port   =   3307   # default port\r\n" +
+		"\r\n" +
+		"[redis:db]\r\n" +
+		"port = 6379\r\n" +
+		"host = 10.0.0.1\r\n" +
+		"\r\n" +
+		"[cache]\r\n" +
+		"size = 10\r\n"
+
+	buf.Reset()
+	config.WriteTo(buf)
+	if buf.String() != expect {
+		t.Errorf("Goini: Not as expected ret=%q, expect=%q", buf.String(), expect)
+	}
+
+	saved, err := LoadBytes(buf.Bytes(), "ini")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		Key     string
+		Section string
+		Expect  string
+	}{
+		{Key: "env", Section: "default", Expect: "prod"},
+		{Key: "name", Section: "default", Expect: "goini"},
+		{Key: "port", Section: "db", Expect: "3307"},
+		{Key: "host", Section: "redis", Expect: "10.0.0.1"},
+		{Key: "port", Section: "redis", Expect: "6379"},
+		{Key: "size", Section: "cache", Expect: "10"},
+	}
+
+	for _, v := range testCases {
+		if ret := saved.GetString(v.Key, v.Section); ret != v.Expect {
+			t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, v.Expect)
+		}
+	}
+}
+
+func TestSet_RoundTrip(t *testing.T) {
+	values := []string{"a # b", "p;w", " lead", "trail ", `say "hi"`, `it's`, `C:\dir\new`}
+
+	config, err := LoadString("env = test\nplain = old\nsingle = 'old'\ndouble = \"old\"\n[db]\nhost = 127.0.0.1\n", "ini")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, val := range values {
+		// 新增的节点及已存在的节点
+		for _, key := range []string{"added", "plain", "single", "double"} {
+			config.Set(key, val)
+			config.Set(key, val, "db")
+		}
+
+		if ret := config.GetString("plain"); ret != val {
+			t.Errorf("Goini: Not as expected ret=%q, expect=%q", ret, val)
+		}
+
+		buf := &bytes.Buffer{}
+		if _, err := config.WriteTo(buf); err != nil {
+			t.Fatal(err)
+		}
+
+		reloaded, err := LoadBytes(buf.Bytes(), "ini")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, key := range []string{"added", "plain", "single", "double"} {
+			if ret := reloaded.GetString(key); ret != val {
+				t.Errorf("Goini: Not as expected key=%v ret=%q, expect=%q\n%s", key, ret, val, buf.String())
+			}
+
+			if ret := reloaded.GetString(key, "db"); ret != val {
+				t.Errorf("Goini: Not as expected key=%v ret=%q, expect=%q\n%s", key, ret, val, buf.String())
+			}
+		}
+	}
+
+	// 引号中不支持转义，换行及同时含有两种引号的值无法写入，内存中的值不受影响
+	for _, val := range []string{"line1\nline2", "it's \"both\""} {
+		config.Set("bad", val, "db")
+
+		if ret := config.GetString("bad", "db"); ret != val {
+			t.Errorf("Goini: Not as expected ret=%q, expect=%q", ret, val)
+		}
+
+		if _, err := config.WriteTo(&bytes.Buffer{}); err == nil {
+			t.Errorf("Goini: Not as expected err=%v", err)
+		}
+	}
+
+	config.Set("bad", "ok", "db")
+	if _, err := config.WriteTo(&bytes.Buffer{}); err != nil {
+		t.Errorf("Goini: Not as expected err=%v", err)
+	}
+}
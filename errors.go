@@ -0,0 +1,148 @@
+package goini
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// 闭合符号不匹配
+	ErrSymbolMismatch = errors.New("symbol mismatch")
+
+	// 节名缺少闭合符号
+	ErrSectionNotClosed = errors.New("section is not closed")
+
+	// 循环引入文件
+	ErrIncludeCycle = errors.New("include cycle")
+
+	// 必填变量 ${key|?message} 未找到
+	ErrMissingVariable = errors.New("missing required variable")
+
+	// 节点不存在
+	ErrNotFound = errors.New("key not found")
+
+	// 节点的值无法转换为指定类型
+	ErrInvalidValue = errors.New("invalid value")
+)
+
+// ParseError 解析错误，记录出错的文件、行号、列号及行内容，可通过 errors.As 获取
+type ParseError struct {
+	File   string
+	Line   int
+	Column int
+	Text   string
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	if e.File == "" {
+		return fmt.Sprintf("goini: %d:%d: %v: %q", e.Line, e.Column, e.Err, e.Text)
+	}
+
+	return fmt.Sprintf("goini: %s:%d:%d: %v: %q", e.File, e.Line, e.Column, e.Err, e.Text)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// NotFoundError 节点不存在，errors.Is(err, ErrNotFound) 为真
+type NotFoundError struct {
+	Key     string
+	Section string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("goini: %s: %v", errorKey(e.Section, e.Key), ErrNotFound)
+}
+
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// ValueError 节点的值无法转换为指定类型，Value 为变量替换后的值，errors.Is(err, ErrInvalidValue) 为真
+type ValueError struct {
+	Key     string
+	Section string
+	Value   string
+	Type    string
+	Err     error
+}
+
+func (e *ValueError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("goini: %s: %v %q for %s", errorKey(e.Section, e.Key), ErrInvalidValue, e.Value, e.Type)
+	}
+
+	return fmt.Sprintf("goini: %s: %v %q for %s: %v", errorKey(e.Section, e.Key), ErrInvalidValue, e.Value, e.Type, e.Err)
+}
+
+func (e *ValueError) Is(target error) bool {
+	return target == ErrInvalidValue
+}
+
+func (e *ValueError) Unwrap() error {
+	return e.Err
+}
+
+// 错误信息中的节点名，默认节不含节名
+func errorKey(section, key string) string {
+	if section == "" || section == defaultName {
+		return key
+	}
+
+	return "[" + section + "] " + key
+}
+
+// 闭合符号错误，记录不匹配符号在文本中的位置
+type symbolError struct {
+	symbol string
+	text   string
+	offset int
+}
+
+func (e *symbolError) Error() string {
+	return fmt.Sprintf("'%s' %v", e.symbol, ErrSymbolMismatch)
+}
+
+func (e *symbolError) Unwrap() error {
+	return ErrSymbolMismatch
+}
+
+/**
+ * 根据当前解析位置生成解析错误
+ * @param rowStr string 出错的行内容
+ * @param err error 原始错误
+ * @return error
+ */
+func (goini *Goini) parseError(rowStr string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	// 已经是解析错误，直接返回
+	var parseErr *ParseError
+	if errors.As(err, &parseErr) {
+		return err
+	}
+
+	column := 1
+
+	var symErr *symbolError
+	if errors.As(err, &symErr) {
+		if pos := strings.Index(rowStr, symErr.text); pos != -1 {
+			column = pos + symErr.offset + 1
+		}
+	} else if pos := strings.IndexFunc(rowStr, func(r rune) bool { return r != ' ' && r != '\t' }); pos != -1 {
+		column = pos + 1
+	}
+
+	return &ParseError{
+		File:   goini.parseName,
+		Line:   goini.lineNum,
+		Column: column,
+		Text:   rowStr,
+		Err:    err,
+	}
+}
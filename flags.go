@@ -0,0 +1,159 @@
+package goini
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Flags 注册在 flag.FlagSet 上的文件路径及 -set 参数
+type Flags struct {
+	defaultFile string
+	paths       []*string
+	overrides   overrideFlags
+}
+
+// 命令行参数的注册选项
+type FlagOptions struct {
+	// 文件路径的参数名，为空时使用 c 及 conf
+	Names []string
+
+	// 覆盖节点的参数名，为空时使用 set
+	SetName string
+
+	// 不注册覆盖节点的参数，应用已经定义了 -set 等同名参数时使用
+	NoSet bool
+}
+
+/**
+ * 在指定的 flag.FlagSet 上注册文件路径参数及可重复的 -set section.key=value 参数
+ * 注册在 flag.CommandLine 上时，New 及 ArgConfigPath 也会读取这些参数
+ * 应用已经定义了 -set 参数时使用 RegisterFlagsWith 改名或不注册，否则 flag 包会panic
+ * @param fs *flag.FlagSet 为nil时使用 flag.CommandLine
+ * @param names ...string 文件路径的参数名，默认为 c 及 conf
+ * @return *Flags 在 fs.Parse 之后取值
+ */
+func RegisterFlags(fs *flag.FlagSet, names ...string) *Flags {
+	return RegisterFlagsWith(fs, FlagOptions{Names: names})
+}
+
+/**
+ * 在指定的 flag.FlagSet 上注册文件路径参数及覆盖节点的参数，参数名由 opts 指定
+ * @param fs *flag.FlagSet 为nil时使用 flag.CommandLine
+ * @param opts FlagOptions 注册选项
+ * @return *Flags 在 fs.Parse 之后取值
+ */
+func RegisterFlagsWith(fs *flag.FlagSet, opts FlagOptions) *Flags {
+	if fs == nil {
+		fs = flag.CommandLine
+	}
+
+	names := opts.Names
+	if len(names) == 0 {
+		names = []string{"c", "conf"}
+	}
+
+	setName := opts.SetName
+	if setName == "" {
+		setName = "set"
+	}
+
+	if opts.NoSet {
+		setName = ""
+	}
+
+	flags := registerFlags(fs, "", setName, names...)
+	if fs == flag.CommandLine {
+		commandLine = flags
+	}
+
+	return flags
+}
+
+// 注册参数，setName 为空时不注册覆盖节点的参数
+func registerFlags(fs *flag.FlagSet, defaultFile, setName string, names ...string) *Flags {
+	flags := &Flags{
+		defaultFile: defaultFile,
+		overrides:   make(overrideFlags),
+	}
+
+	for _, name := range names {
+		flags.paths = append(flags.paths, fs.String(name, "", "config file path"))
+	}
+
+	if setName != "" {
+		fs.Var(flags.overrides, setName, "override key, section.key=value (repeatable)")
+	}
+
+	return flags
+}
+
+/**
+ * 命令行中的文件路径，按注册的顺序取第一个不为空的参数
+ * @return string 都未设置时返回默认文件路径
+ */
+func (f *Flags) Path() string {
+	for _, path := range f.paths {
+		if *path != "" {
+			return *path
+		}
+	}
+
+	return f.defaultFile
+}
+
+/**
+ * 命令行中 -set 覆盖的节点
+ * @return map[string]string 未注册覆盖节点的参数时返回空map
+ */
+func (f *Flags) Overrides() map[string]string {
+	overrides := make(map[string]string, len(f.overrides))
+	for key, val := range f.overrides {
+		overrides[key] = val
+	}
+
+	return overrides
+}
+
+/**
+ * 加载命令行中指定的文件并应用 -set 覆盖的节点
+ * @param syntax string 文件格式
+ * @param opts ...Option 加载选项
+ * @return *Goini, error 未指定文件时返回错误
+ */
+func (f *Flags) Load(syntax string, opts ...Option) (*Goini, error) {
+	path := f.Path()
+	if path == "" {
+		return nil, errors.New("goini: config file path is not set")
+	}
+
+	// 覆盖的节点最后应用，与选项的顺序无关
+	return LoadFile(path, syntax, append([]Option{WithOverrides(f.Overrides())}, opts...)...)
+}
+
+// 命令行中覆盖的节点，实现 flag.Value
+type overrideFlags map[string]string
+
+func (o overrideFlags) String() string {
+	items := make([]string, 0, len(o))
+	for key, val := range o {
+		items = append(items, key+"="+val)
+	}
+
+	sort.Strings(items)
+
+	return strings.Join(items, ",")
+}
+
+func (o overrideFlags) Set(arg string) error {
+	pos := strings.Index(arg, "=")
+	if pos <= 0 {
+		return fmt.Errorf("invalid override %q, expected section.key=value", arg)
+	}
+
+	o[strings.TrimSpace(arg[:pos])] = strings.TrimSpace(arg[pos+1:])
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package goini
+
+import (
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestRegisterFlags(t *testing.T) {
+	// 导入时不在 flag.CommandLine 上注册参数
+	for _, name := range []string{"c", "conf", "set"} {
+		if flag.CommandLine.Lookup(name) != nil {
+			t.Errorf("Goini: Not as expected flag=%v", name)
+		}
+	}
+
+	if ret := ArgConfigPath(); ret != "" {
+		t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, "")
+	}
+
+	path := filepath.Join(t.TempDir(), "app.ini")
+	ioutil.WriteFile(path, []byte("env = dev\n[database]\ndb.host = 127.0.0.1\n"), 0644)
+
+	testCases := []struct {
+		Names  []string
+		Args   []string
+		Expect string
+	}{
+		{Args: []string{"-c", path}, Expect: path},
+		{Args: []string{"-conf", path}, Expect: path},
+		{Names: []string{"config"}, Args: []string{"-config", path}, Expect: path},
+		{Names: []string{"config"}, Args: []string{}, Expect: ""},
+	}
+
+	for _, v := range testCases {
+		fs := flag.NewFlagSet("app", flag.ContinueOnError)
+		fs.SetOutput(ioutil.Discard)
+
+		flags := RegisterFlags(fs, v.Names...)
+		if err := fs.Parse(v.Args); err != nil {
+			t.Fatal(err)
+		}
+
+		if ret := flags.Path(); ret != v.Expect {
+			t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, v.Expect)
+		}
+	}
+
+	// 与应用自己的参数共存
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+
+	count := fs.Int("n", 0, "count")
+	flags := RegisterFlags(fs, "config")
+
+	if err := fs.Parse([]string{"-n", "3", "-config", path, "-set", "database.db.host=10.0.0.2", "-set", "env=prod"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if *count != 3 {
+		t.Errorf("Goini: Not as expected ret=%v, expect=%v", *count, 3)
+	}
+
+	config, err := flags.Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ret := config.GetString("db.host", "database"); ret != "10.0.0.2" {
+		t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, "10.0.0.2")
+	}
+
+	if ret := config.GetString("env"); ret != "prod" {
+		t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, "prod")
+	}
+
+	if _, err := RegisterFlags(flag.NewFlagSet("app", flag.ContinueOnError)).Load(""); err == nil {
+		t.Errorf("Goini: Not as expected err=%v", err)
+	}
+}
+
+func TestInitFlag(t *testing.T) {
+	flags, flagSet := commandLine, flag.CommandLine
+	defer func() {
+		commandLine, flag.CommandLine = flags, flagSet
+	}()
+
+	path := filepath.Join(t.TempDir(), "app.ini")
+
+	// -c 与 flagArg 同时注册，-c 优先
+	testCases := []struct {
+		Args   []string
+		Expect string
+	}{
+		{Args: []string{"-c", path}, Expect: path},
+		{Args: []string{"-config", path}, Expect: path},
+		{Args: []string{"-c", path, "-config", "other.ini"}, Expect: path},
+		{Args: []string{}, Expect: "app.ini"},
+	}
+
+	for _, v := range testCases {
+		flag.CommandLine = flag.NewFlagSet("app", flag.ContinueOnError)
+		flag.CommandLine.SetOutput(ioutil.Discard)
+
+		InitFlag("config", "app.ini")
+
+		if err := flag.CommandLine.Parse(v.Args); err != nil {
+			t.Fatal(err)
+		}
+
+		if ret := ArgConfigPath(); ret != v.Expect {
+			t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, v.Expect)
+		}
+	}
+}
+
+func TestRegisterFlagsWith(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.ini")
+	ioutil.WriteFile(path, []byte("env = dev\n"), 0644)
+
+	// 应用自己定义了 -set 参数，不注册或改名后不会冲突
+	testCases := []struct {
+		Options FlagOptions
+		Args    []string
+		Expect  string
+	}{
+		{Options: FlagOptions{NoSet: true}, Args: []string{"-c", path, "-set", "mode=fast"}, Expect: "dev"},
+		{Options: FlagOptions{SetName: "config-set"}, Args: []string{"-c", path, "-set", "mode=fast", "-config-set", "env=prod"}, Expect: "prod"},
+		{Options: FlagOptions{Names: []string{"config"}, SetName: "override"}, Args: []string{"-config", path, "-override", "env=test", "-set", "mode=fast"}, Expect: "test"},
+	}
+
+	for _, v := range testCases {
+		fs := flag.NewFlagSet("app", flag.ContinueOnError)
+		fs.SetOutput(ioutil.Discard)
+
+		set := fs.String("set", "", "application flag")
+		flags := RegisterFlagsWith(fs, v.Options)
+
+		if err := fs.Parse(v.Args); err != nil {
+			t.Fatal(err)
+		}
+
+		if *set != "mode=fast" {
+			t.Errorf("Goini: Not as expected ret=%v, expect=%v", *set, "mode=fast")
+		}
+
+		config, err := flags.Load("")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if ret := config.GetString("env"); ret != v.Expect {
+			t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, v.Expect)
+		}
+	}
+}
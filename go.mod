@@ -1,3 +1,3 @@
 module github.com/vcqr/goini
 
-go 1.12
+go 1.16
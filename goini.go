@@ -2,11 +2,13 @@ package goini
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -14,6 +16,8 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 type Config interface {
@@ -54,34 +58,103 @@ type Config interface {
 	GetStruct(key string, targetObj interface{}, args ...interface{})
 }
 
+// Goini 配置对象，所有方法均可并发调用
 type Goini struct {
 	filePath string
 	Syntax   string
-}
 
-// 默认ini文件
-var defaultIni string = "application.ini"
+	// 读写锁，保证并发读写安全
+	mu sync.RWMutex
+
+	// 存储节内容
+	sections map[string]interface{}
+
+	// 存储节点内容，解析时指向当前节
+	property map[string]interface{}
+
+	// 记录当前节名
+	sectionName string
+
+	// 记录yaml当前行数据
+	lineNode LineNode
+
+	// 记录toml当前行数据
+	tomlLineNode TomlLineNode
+
+	// toml数组表格索引
+	idxMap map[string]string
+
+	// 当前解析的文件名、行号及行内容
+	parseName string
+	lineNum   int
+	lineText  string
+
+	// 解析时记录的必填变量 ${key|?message}，合并及应用选项后检查
+	required []requiredVar
+
+	// 记录每个节点的来源文件
+	origins map[string]map[string]string
+
+	// 继承父节的节，按出现的顺序记录，合并多个文件后重新继承
+	inherits []inheritance
 
-// 默认参数 -c
-var iniPath = flag.String("c", "", "ini file path")
+	// 继承父节的节自身的节点，不含从父节继承的节点
+	own map[string]map[string]interface{}
 
-// 默认参数 -conf
-var confPath = flag.String("conf", "", "ini file path")
+	// 文件系统，为空时使用本地文件
+	fsys fs.FS
 
-// 存储节内容
-var sections map[string]interface{}
+	// 正在解析的引入文件链，用于检测循环引入
+	includes []string
+
+	// 加载过的文件及目录，用于监听变化
+	files []string
+
+	// 重新加载配置的方法，opts 为需要再次应用的选项
+	loader func(opts []Option) (*Goini, error)
+
+	// 配置变化时的回调
+	onChange []func(old, new *Goini)
+
+	// 重新加载时的校验方法
+	validators []func(*Goini) error
+
+	// ini文件的语法树，用于无损写回
+	doc *document
+
+	// 加载选项，重新加载时再次应用
+	options []Option
+
+	// 覆盖的节点，优先级最高
+	overrides map[string]string
+
+	// WithEnv 等选项生成环境变量名的方法，解析完成后依次应用
+	envKeys []func(section, key string) string
+
+	// 应用覆盖的节点及环境变量前的节内容，保存时写入，为空时与 sections 相同
+	base map[string]interface{}
+
+	// ${env:NAME} 环境变量的读取权限，为空时不读取任何环境变量
+	envAllow func(name string) bool
+}
+
+// 默认ini文件
+var defaultIni string = "application.ini"
 
-// 存储节点内容
-var property map[string]interface{}
+// 注册在 flag.CommandLine 上的参数，调用 InitFlag 或 RegisterFlags(flag.CommandLine) 后才会注册
+var commandLine *Flags
 
-// 存储父节内容
-var parentMap map[string]interface{}
+// 最近一次通过 New 或 Load 加载的配置，供已废弃的包级函数使用
+var lastLoaded struct {
+	sync.Mutex
+	config *Goini
+}
 
 // 默认节名
 const defaultName = "default"
 
-// 记录当前节名
-var sectionName string
+// 环境变量的命名空间 ${env:NAME}
+const envNamespace = "env"
 
 var syntaxMap = map[string]string{
 	"yaml": "yml",
@@ -103,6 +176,29 @@ var syntaxMap = map[string]string{
  * @return interface{}
  */
 func (goini *Goini) Get(key string, args ...interface{}) interface{} {
+	goini.mu.RLock()
+	defer goini.mu.RUnlock()
+
+	// 返回副本，避免调用方持有内部map时与Set产生竞争
+	return copyValue(goini.get(key, args...))
+}
+
+/**
+ * 取值并替换其中的 ${key}、${section:key}、${env:NAME} 变量，map及切片中的变量也会被替换
+ * 变量可以使用 ${key|default} 指定默认值
+ * @param key string 节点名称
+ * @param args 可变参数，第一个参数为节名，第二个参数为默认值
+ * @return interface{} 未找到时返回nil
+ */
+func (goini *Goini) Resolve(key string, args ...interface{}) interface{} {
+	goini.mu.RLock()
+	defer goini.mu.RUnlock()
+
+	return goini.resolveValue(goini.get(key, args...))
+}
+
+// 取值，调用方需持有锁
+func (goini *Goini) get(key string, args ...interface{}) interface{} {
 	var retVal interface{}
 
 	argLen := len(args)
@@ -131,23 +227,56 @@ func (goini *Goini) Get(key string, args ...interface{}) interface{} {
  * @param args 可变参数，当长度大于0，则设置多个节
  */
 func (goini *Goini) Set(key string, val interface{}, args ...interface{}) {
+	goini.mu.Lock()
+	defer goini.mu.Unlock()
+
+	valStr := fmt.Sprintf("%v", val)
+
 	if len(args) > 0 {
 		for _, arg := range args {
-			goini.setValBySection(key, fmt.Sprintf("%v", val), fmt.Sprintf("%v", arg))
+			goini.setValBySection(key, valStr, fmt.Sprintf("%v", arg))
+			goini.setDocument(key, valStr)
+			goini.setBase(key, valStr, fmt.Sprintf("%v", arg))
 		}
 	} else {
-		goini.setValBySection(key, fmt.Sprintf("%v", val), defaultName)
+		goini.setValBySection(key, valStr, defaultName)
+		goini.setDocument(key, valStr)
+		goini.setBase(key, valStr, defaultName)
 	}
 
 }
 
+// 同步修改语法树，节为 setValBySection 解析后的当前节
+func (goini *Goini) setDocument(key, valStr string) {
+	if goini.doc != nil {
+		goini.doc.set(goini.sectionName, key, valStr)
+	}
+}
+
+// 同步修改覆盖前的节内容，保存时写入设置的值
+func (goini *Goini) setBase(key, valStr, section string) {
+	if goini.base == nil {
+		return
+	}
+
+	sections, sectionName, property := goini.sections, goini.sectionName, goini.property
+
+	goini.sections = goini.base
+	goini.setValBySection(key, valStr, section)
+
+	goini.sections, goini.sectionName, goini.property = sections, sectionName, property
+}
+
 /**
  * 获取指定节内容
  * @param section string 节名
  * @return map[string]interface{}
  */
 func (goini *Goini) GetSection(section string) map[string]interface{} {
-	return GetSection(section)
+	goini.mu.RLock()
+	defer goini.mu.RUnlock()
+
+	return goini.getSection(section)
 }
 
 /**
@@ -157,7 +286,20 @@ func (goini *Goini) GetSection(section string) map[string]interface{} {
  * @return interface{} 混合类型内容
  */
 func (goini *Goini) getValBySection(key string, section string) interface{} {
-	return getValBySection(key, section)
+	if section == "" || section == "<nil>" {
+		section = defaultName
+	}
+
+	if tempRet, ok := goini.sections[section].(map[string]interface{}); ok {
+		if mp, ok := tempRet[key]; ok {
+			return mp
+		} else {
+			keyArr := strings.Split(key, ".")
+			return getMapVal(keyArr, tempRet, 0)
+		}
+	}
+
+	return nil
 }
 
 /**
@@ -176,19 +318,31 @@ func (goini *Goini) setValBySection(key string, val interface{}, section string)
 	}
 
 	//设置节点
-	parseSection(section)
+	goini.parseSection(section)
+
+	// 含有注释符号、首尾空白等内容的值加上引号后解析，无法写为ini的值及无法解析的值按原样保存
+	valStr := fmt.Sprintf("%v", val)
+
+	text, err := iniText(valStr)
+	if err == nil {
+		err = goini.parseProperty(key + "=" + text)
+	}
 
-	// 设置属性
-	parseProperty(key + "=" + fmt.Sprintf("%v", val))
+	if err != nil {
+		goini.setProperty(parsNodeName(key), valStr)
+	}
 }
 
 // 返回string类型的值
 func (goini *Goini) GetString(key string, args ...interface{}) string {
-	val := goini.Get(key, args...)
+	goini.mu.RLock()
+	defer goini.mu.RUnlock()
+
+	val := goini.get(key, args...)
 
 	if valStr, ok := val.(string); ok {
 
-		valStr = decodeVariable(valStr)
+		valStr = goini.decodeVariable(valStr)
 
 		return valStr
 	}
@@ -198,48 +352,225 @@ func (goini *Goini) GetString(key string, args ...interface{}) string {
 
 // 返回int64类型的值
 func (goini *Goini) GetInt(key string, args ...interface{}) int64 {
-	val := goini.Get(key, args...)
+	ret, _ := goini.GetIntE(key, args...)
 
-	if valStr, ok := val.(string); ok {
+	return ret
+}
 
-		valStr = decodeVariable(valStr)
+// 返回float64类型的值
+func (goini *Goini) GetFloat(key string, args ...interface{}) float64 {
+	ret, _ := goini.GetFloatE(key, args...)
 
-		if floatVal, err := strconv.ParseFloat(valStr, 64); err == nil {
-			return int64(floatVal)
-		}
+	return ret
+}
+
+// 返回bool类型的值
+func (goini *Goini) GetBool(key string, args ...interface{}) bool {
+	ret, _ := goini.GetBoolE(key, args...)
+
+	return ret
+}
+
+/**
+ * 查找节点，区分节点不存在与值为空
+ * @param key string 节点名
+ * @param section string 节名，为空时取默认节
+ * @return interface{}, bool 值为原始内容，与 Get 相同
+ */
+func (goini *Goini) Lookup(key, section string) (interface{}, bool) {
+	goini.mu.RLock()
+	defer goini.mu.RUnlock()
+
+	val := goini.getValBySection(key, section)
+	if val == nil {
+		return nil, false
 	}
 
-	return 0
+	return copyValue(val), true
 }
 
-// 返回float64类型的值
-func (goini *Goini) GetFloat(key string, args ...interface{}) float64 {
-	val := goini.Get(key, args...)
+/**
+ * 返回string类型的值，节点不存在时返回 *NotFoundError，值为map或切片时返回 *ValueError
+ * @param key string 节点名
+ * @param args 可变参数，第一个参数为节名，第二个参数为默认值
+ * @return string, error
+ */
+func (goini *Goini) GetStringE(key string, args ...interface{}) (string, error) {
+	goini.mu.RLock()
+	defer goini.mu.RUnlock()
 
-	if valStr, ok := val.(string); ok {
+	valStr, _, err := goini.getScalar(key, "string", args)
 
-		valStr = decodeVariable(valStr)
+	return valStr, err
+}
 
-		if floatVal, err := strconv.ParseFloat(valStr, 64); err == nil {
-			return floatVal
-		}
+/**
+ * 返回int64类型的值，节点不存在时返回 *NotFoundError，无法转换时返回 *ValueError
+ * @param key string 节点名
+ * @param args 可变参数，第一个参数为节名，第二个参数为默认值
+ * @return int64, error
+ */
+func (goini *Goini) GetIntE(key string, args ...interface{}) (int64, error) {
+	goini.mu.RLock()
+	defer goini.mu.RUnlock()
+
+	valStr, section, err := goini.getScalar(key, "int64", args)
+	if err != nil {
+		return 0, err
 	}
 
-	return 0
+	intVal, err := parseIntString(valStr, 64)
+	if err != nil {
+		return 0, &ValueError{Key: key, Section: section, Value: valStr, Type: "int64", Err: numError(err)}
+	}
+
+	return intVal, nil
 }
 
-// 返回bool类型的值
-func (goini *Goini) GetBool(key string, args ...interface{}) bool {
-	val := goini.Get(key, args...)
+/**
+ * 返回float64类型的值，节点不存在时返回 *NotFoundError，无法转换时返回 *ValueError
+ * @param key string 节点名
+ * @param args 可变参数，第一个参数为节名，第二个参数为默认值
+ * @return float64, error
+ */
+func (goini *Goini) GetFloatE(key string, args ...interface{}) (float64, error) {
+	goini.mu.RLock()
+	defer goini.mu.RUnlock()
+
+	valStr, section, err := goini.getScalar(key, "float64", args)
+	if err != nil {
+		return 0, err
+	}
+
+	floatVal, err := strconv.ParseFloat(strings.TrimSpace(valStr), 64)
+	if err != nil {
+		return 0, &ValueError{Key: key, Section: section, Value: valStr, Type: "float64", Err: numError(err)}
+	}
 
-	ret, _ := parseBool(val)
+	return floatVal, nil
+}
+
+/**
+ * 返回bool类型的值，支持 yes/no、on/off 等常用的词，节点不存在时返回 *NotFoundError，无法转换时返回 *ValueError
+ * @param key string 节点名
+ * @param args 可变参数，第一个参数为节名，第二个参数为默认值
+ * @return bool, error
+ */
+func (goini *Goini) GetBoolE(key string, args ...interface{}) (bool, error) {
+	goini.mu.RLock()
+	defer goini.mu.RUnlock()
+
+	valStr, section, err := goini.getScalar(key, "bool", args)
+	if err != nil {
+		return false, err
+	}
+
+	boolVal, err := goini.parseBool(valStr)
+	if err != nil {
+		return false, &ValueError{Key: key, Section: section, Value: valStr, Type: "bool"}
+	}
+
+	return boolVal, nil
+}
+
+// 返回time.Duration类型的值，值为 5s、1h30m 等格式
+func (goini *Goini) GetDuration(key string, args ...interface{}) time.Duration {
+	ret, _ := goini.GetDurationE(key, args...)
 
 	return ret
 }
 
+/**
+ * 返回time.Duration类型的值，节点不存在时返回 *NotFoundError，无法转换时返回 *ValueError
+ * @param key string 节点名
+ * @param args 可变参数，第一个参数为节名，第二个参数为默认值
+ * @return time.Duration, error
+ */
+func (goini *Goini) GetDurationE(key string, args ...interface{}) (time.Duration, error) {
+	goini.mu.RLock()
+	defer goini.mu.RUnlock()
+
+	valStr, section, err := goini.getScalar(key, "time.Duration", args)
+	if err != nil {
+		return 0, err
+	}
+
+	duration, err := time.ParseDuration(strings.TrimSpace(valStr))
+	if err != nil {
+		return 0, &ValueError{Key: key, Section: section, Value: valStr, Type: "time.Duration", Err: err}
+	}
+
+	return duration, nil
+}
+
+// 返回字节大小，值为 10MB、1.5GiB 等格式
+func (goini *Goini) GetBytes(key string, args ...interface{}) ByteSize {
+	ret, _ := goini.GetBytesE(key, args...)
+
+	return ret
+}
+
+/**
+ * 返回字节大小，节点不存在时返回 *NotFoundError，无法转换时返回 *ValueError
+ * @param key string 节点名
+ * @param args 可变参数，第一个参数为节名，第二个参数为默认值
+ * @return ByteSize, error
+ */
+func (goini *Goini) GetBytesE(key string, args ...interface{}) (ByteSize, error) {
+	goini.mu.RLock()
+	defer goini.mu.RUnlock()
+
+	valStr, section, err := goini.getScalar(key, "goini.ByteSize", args)
+	if err != nil {
+		return 0, err
+	}
+
+	size, err := ParseByteSize(valStr)
+	if err != nil {
+		return 0, &ValueError{Key: key, Section: section, Value: valStr, Type: "goini.ByteSize", Err: numError(err)}
+	}
+
+	return size, nil
+}
+
+// 只保留 strconv.ErrRange 等原因
+func numError(err error) error {
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		return numErr.Err
+	}
+
+	return err
+}
+
+// 取值并替换变量，返回值及节名，调用方需持有锁
+func (goini *Goini) getScalar(key, typeName string, args []interface{}) (string, string, error) {
+	section := defaultName
+	if len(args) > 0 && args[0] != nil && args[0] != "" {
+		section = fmt.Sprintf("%v", args[0])
+	}
+
+	val := goini.get(key, args...)
+
+	switch v := val.(type) {
+	case nil:
+		return "", section, &NotFoundError{Key: key, Section: section}
+	case string:
+		return goini.decodeVariable(v), section, nil
+	case map[string]interface{}, []interface{}, []map[string]interface{}:
+		return "", section, &ValueError{Key: key, Section: section, Value: formatChangeValue(v), Type: typeName}
+	}
+
+	// 默认值可以是其他类型
+	return fmt.Sprintf("%v", val), section, nil
+}
+
 // 转换为切片类型
 func (goini *Goini) GetSlice(key string, delimiter string, targetObj interface{}, args ...interface{}) {
-	val := goini.Get(key, args...)
+	goini.mu.RLock()
+	defer goini.mu.RUnlock()
+
+	val := goini.get(key, args...)
 
 	objV := reflect.ValueOf(targetObj)
 	objT := reflect.TypeOf(targetObj)
@@ -261,14 +592,18 @@ func (goini *Goini) GetSlice(key string, delimiter string, targetObj interface{}
 		return
 	}
 
-	if retVal, err := parseSlice(val, objT, delimiter); err == nil {
+	// 无法转换的元素为零值
+	if retVal, err := goini.parseSlice(val, objT, delimiter); err == nil || isValueError(err) {
 		objV.Set(retVal)
 	}
 }
 
 // 转化为map类型，obj引用传值
 func (goini *Goini) GetMap(key string, targetObj interface{}, args ...interface{}) {
-	val := goini.Get(key, args...)
+	goini.mu.RLock()
+	defer goini.mu.RUnlock()
+
+	val := goini.get(key, args...)
 
 	objV := reflect.ValueOf(targetObj)
 	objT := reflect.TypeOf(targetObj)
@@ -286,25 +621,83 @@ func (goini *Goini) GetMap(key string, targetObj interface{}, args ...interface{
 		return
 	}
 
-	if retVal, err := parseMap(val, objT); err == nil {
+	// 无法转换的元素为零值
+	if retVal, err := goini.parseMap(val, objT); err == nil || isValueError(err) {
 		objV.Set(retVal)
 	}
 }
 
 // 转化为结构体类型，obj引用传值
 func (goini *Goini) GetStruct(key string, targetObj interface{}, args ...interface{}) {
-	val := goini.Get(key, args...)
+	goini.mu.RLock()
+	defer goini.mu.RUnlock()
+
+	val := goini.get(key, args...)
 
 	if valMap, ok := val.(map[string]interface{}); ok {
-		mapToStruct(key, valMap, targetObj)
+		goini.mapToStruct(key, valMap, targetObj)
 	}
 }
 
 /**
- * 构造对象
+ * 转化为结构体类型，与 GetStruct 相同，但会返回第一个无法转换的字段
+ * 整数字段超出范围或值为小数时返回 *ValueError，其他字段仍会被设置
+ * @param key string 节点名
+ * @param targetObj interface{} 结构体指针
+ * @param args 可变参数，第一个参数为节名
+ * @return error 节点不存在时返回 *NotFoundError
+ */
+func (goini *Goini) GetStructE(key string, targetObj interface{}, args ...interface{}) error {
+	goini.mu.RLock()
+	defer goini.mu.RUnlock()
+
+	section := defaultName
+	if len(args) > 0 && args[0] != nil && args[0] != "" {
+		section = fmt.Sprintf("%v", args[0])
+	}
+
+	val := goini.get(key, args...)
+	if val == nil {
+		return &NotFoundError{Key: key, Section: section}
+	}
+
+	valMap, ok := val.(map[string]interface{})
+	if !ok {
+		return &ValueError{Key: key, Section: section, Value: formatChangeValue(val), Type: reflect.TypeOf(targetObj).String()}
+	}
+
+	err := goini.mapToStruct(key, valMap, targetObj)
+
+	var valueErr *ValueError
+	if errors.As(err, &valueErr) {
+		valueErr.Key = joinKey(key, valueErr.Key)
+		valueErr.Section = section
+	}
+
+	return err
+}
+
+/**
+ * 构造对象，文件路径取自 InitFlag 或 RegisterFlags(flag.CommandLine) 注册的命令行参数
+ * 未注册时不解析命令行，使用执行文件所在目录下的 application.ini
+ * 无法确定执行文件所在目录、文件不存在或解析失败时panic，需要处理错误时使用 NewE
  * @return *Goini
  */
 func New(syntax string) *Goini {
+	config, err := NewE(syntax)
+	if err != nil {
+		panic("goini error: " + err.Error())
+	}
+
+	return config
+}
+
+/**
+ * 构造对象，文件路径的规则与 New 相同，出错时返回错误而不是panic
+ * @param syntax string 文件格式
+ * @return *Goini, error
+ */
+func NewE(syntax string) (*Goini, error) {
 	var path string
 
 	// 命令行 获取文件
@@ -314,72 +707,240 @@ func New(syntax string) *Goini {
 		// 执行目录当前查找
 		currentPath, err := GetCurrentPath()
 		if err != nil {
-			panic("goini error: ini file cannot be nil")
+			return nil, fmt.Errorf("goini: cannot locate %s: %w", defaultIni, err)
 		}
 
 		path = currentPath + defaultIni
 	}
 
-	return Load(path, syntax)
+	config, err := LoadFile(path, syntax, WithOverrides(ArgOverrides()))
+	if err != nil {
+		return nil, err
+	}
+
+	lastLoaded.Lock()
+	lastLoaded.config = config
+	lastLoaded.Unlock()
 
+	return config, nil
 }
 
+/**
+ * 在 flag.CommandLine 上注册文件路径参数、-c 参数及 -set 参数，之后 New 及 ArgConfigPath 会读取命令行
+ * 与之前的版本一致，-c 优先于 flagArg；应用已经定义了 -c 或 -set 参数时不再注册，由应用处理
+ * @param flagArg string 参数名
+ * @param defaultFile string 默认文件路径
+ */
 func InitFlag(flagArg, defaultFile string) {
-	confPath = flag.String(flagArg, defaultFile, "conf path")
+	var names []string
+	if flagArg != "c" && flag.CommandLine.Lookup("c") == nil {
+		names = append(names, "c")
+	}
+
+	setName := "set"
+	if flag.CommandLine.Lookup(setName) != nil {
+		setName = ""
+	}
+
+	commandLine = registerFlags(flag.CommandLine, defaultFile, setName, append(names, flagArg)...)
 }
 
 /**
- * 加载指定文件
+ * 初始化对象，所有解析状态都保存在对象内
+ * @param path string 文件路径
+ * @param syntax string 文件格式
+ * @return *Goini
+ */
+func newGoini(path, syntax string) *Goini {
+	goini := &Goini{
+		filePath: path,
+		sections: make(map[string]interface{}),
+		idxMap:   make(map[string]string),
+		origins:  make(map[string]map[string]string),
+		own:      make(map[string]map[string]interface{}),
+	}
+
+	// 未指定格式时，解析时再推断
+	if syntax != "" {
+		goini.Syntax = syntaxMap[syntax]
+	}
+
+	// 初始化节点属性
+	goini.property = make(map[string]interface{})
+
+	goini.sectionName = defaultName
+
+	goini.sections[goini.sectionName] = goini.property
+
+	goini.tomlLineNode = TomlLineNode{
+		KeyName: defaultName,
+	}
+
+	return goini
+}
+
+/**
+ * 加载指定文件，出错时panic
  * @param path string 文件路径
+ * @param opts ...Option 加载选项
  * @return *Goini
  */
-func Load(path, syntax string) *Goini {
+func Load(path, syntax string, opts ...Option) *Goini {
 	// 文件是否存在
 	isFile, _ := PathExists(path)
 	if !isFile {
 		panic("goini error: " + path + " not exists")
 	}
 
-	config := &Goini{
-		filePath: path,
+	config, err := LoadFile(path, syntax, opts...)
+	if err != nil {
+		panic("goini error: file parse error \r\n err:" + err.Error())
 	}
 
-	// 初始化节
-	sections = make(map[string]interface{})
-
-	// 初始化节点属性
-	property = make(map[string]interface{})
+	lastLoaded.Lock()
+	lastLoaded.config = config
+	lastLoaded.Unlock()
 
-	sectionName = defaultName
+	return config
+}
 
-	sections[sectionName] = property
+/**
+ * 加载指定文件
+ * @param path string 文件路径
+ * @param syntax string 文件格式
+ * @param opts ...Option 加载选项
+ * @return *Goini, error 解析失败时返回 *ParseError
+ */
+func LoadFile(path, syntax string, opts ...Option) (*Goini, error) {
+	config := newGoini(path, syntax)
+	config.configure(opts)
 
 	// 解析文件
-	err := parseFile(config.filePath, syntax)
+	if err := config.parseFile(config.filePath, syntax); err != nil {
+		return nil, err
+	}
+
+	if err := config.finish(); err != nil {
+		return nil, err
+	}
+
+	config.loader = func(opts []Option) (*Goini, error) {
+		return LoadFile(path, syntax, opts...)
+	}
+
+	return config, nil
+}
+
+/**
+ * 从io.Reader加载配置
+ * @param r io.Reader 数据源
+ * @param syntax string 文件格式
+ * @param opts ...Option 加载选项
+ * @return *Goini, error
+ */
+func LoadReader(r io.Reader, syntax string, opts ...Option) (*Goini, error) {
+	config := newGoini("", syntax)
+	config.configure(opts)
+
+	if err := config.parseReader(r, "", syntax); err != nil {
+		return nil, err
+	}
+
+	if err := config.finish(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+/**
+ * 从字节内容加载配置
+ * @param data []byte 配置内容
+ * @param syntax string 文件格式
+ * @param opts ...Option 加载选项
+ * @return *Goini, error
+ */
+func LoadBytes(data []byte, syntax string, opts ...Option) (*Goini, error) {
+	return LoadReader(bytes.NewReader(data), syntax, opts...)
+}
+
+/**
+ * 从字符串加载配置
+ * @param data string 配置内容
+ * @param syntax string 文件格式
+ * @param opts ...Option 加载选项
+ * @return *Goini, error
+ */
+func LoadString(data string, syntax string, opts ...Option) (*Goini, error) {
+	return LoadReader(strings.NewReader(data), syntax, opts...)
+}
+
+/**
+ * 从文件系统加载配置，如 embed.FS，文件格式根据扩展名或内容推断
+ * @param fsys fs.FS 文件系统
+ * @param name string 文件名
+ * @param opts ...Option 加载选项
+ * @return *Goini, error
+ */
+func LoadFS(fsys fs.FS, name string, opts ...Option) (*Goini, error) {
+	fp, err := fsys.Open(name)
 	if err != nil {
-		panic("goini error: file parse error \r\n err:" + err.Error())
+		return nil, err
 	}
 
-	return config
+	defer fp.Close()
+
+	config := newGoini(name, "")
+	config.fsys = fsys
+	config.files = append(config.files, name)
+	config.configure(opts)
+
+	if err := config.parseReader(fp, name, ""); err != nil {
+		return nil, err
+	}
+
+	if err := config.finish(); err != nil {
+		return nil, err
+	}
+
+	config.loader = func(opts []Option) (*Goini, error) {
+		return LoadFS(fsys, name, opts...)
+	}
+
+	return config, nil
 }
 
 /**
- * 获取命令行中的文件路径
+ * 获取命令行中的文件路径，未调用 InitFlag 或 RegisterFlags(flag.CommandLine) 时返回空
+ * 命令行尚未解析时会调用 flag.Parse()
  * @return string
  */
 func ArgConfigPath() string {
+	if commandLine == nil {
+		return ""
+	}
+
 	if !flag.Parsed() {
 		flag.Parse()
 	}
 
-	path := *iniPath
-	if path != "" {
-		return path
+	return commandLine.Path()
+}
+
+/**
+ * 获取命令行中 -set section.key=value 覆盖的节点，未调用 InitFlag 或 RegisterFlags(flag.CommandLine) 时返回空
+ * @return map[string]string
+ */
+func ArgOverrides() map[string]string {
+	if commandLine == nil {
+		return nil
 	}
 
-	path = *confPath
+	if !flag.Parsed() {
+		flag.Parse()
+	}
 
-	return path
+	return commandLine.Overrides()
 }
 
 /**
@@ -431,7 +992,7 @@ func GetCurrentPath() (string, error) {
  * 解析文件内容
  * @param filePath string 要解析的文件
  */
-func parseFile(filePath, syntax string) error {
+func (goini *Goini) parseFile(filePath, syntax string) error {
 
 	fp, err := os.Open(filePath)
 	if err != nil {
@@ -440,45 +1001,144 @@ func parseFile(filePath, syntax string) error {
 
 	defer fp.Close()
 
-	br := bufio.NewReader(fp)
+	goini.files = append(goini.files, filePath)
+
+	return goini.parseReader(fp, filePath, syntax)
+}
+
+/**
+ * 解析数据流内容
+ * @param r io.Reader 数据源
+ * @param name string 数据源名称，用于错误提示
+ * @param syntax string 文件格式
+ */
+func (goini *Goini) parseReader(r io.Reader, name, syntax string) error {
+	// 每个数据源都从默认节开始解析
+	goini.sectionName = defaultName
+	goini.property, _ = goini.sections[defaultName].(map[string]interface{})
+	if goini.property == nil {
+		goini.property = make(map[string]interface{})
+		goini.sections[defaultName] = goini.property
+	}
+
+	// 保留原始内容，ini格式可以无损写回
+	raw := &bytes.Buffer{}
+	err := goini.parseLines(io.TeeReader(r, raw), name, syntax)
+	if err == nil && goini.Syntax == "ini" {
+		goini.doc = parseDocument(raw.Bytes())
+	}
+
+	goini.parseName = ""
+	goini.lineNum = 0
+	goini.lineText = ""
+	goini.property = nil
+	goini.lineNode = LineNode{}
+	goini.tomlLineNode = TomlLineNode{
+		KeyName: defaultName,
+	}
+
+	return err
+}
+
+/**
+ * 逐行解析数据，从当前节开始
+ * @param r io.Reader 数据源
+ * @param name string 数据源名称，用于错误提示
+ * @param syntax string 文件格式
+ */
+func (goini *Goini) parseLines(r io.Reader, name, syntax string) error {
+	var err error
+
+	// 未指定格式时，根据扩展名或内容推断
+	if syntax == "" {
+		syntax = syntaxByExt(name)
+	}
+
+	if syntax == "" {
+		content, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+
+		syntax = sniffSyntax(content)
+		r = bytes.NewReader(content)
+	}
+
+	if goini.Syntax == "" {
+		goini.Syntax = syntaxMap[syntax]
+	}
+
+	br := bufio.NewReader(r)
+
+	goini.parseName = name
+	goini.lineNum = 0
 
 	for {
-		row, _, end := br.ReadLine()
+		row, end := readLine(br)
 
 		if end == io.EOF {
 			break
 		}
 
+		if end != nil {
+			return end
+		}
+
+		goini.lineNum++
+		goini.lineText = string(row)
+
 		targetSyntax := syntaxMap[syntax]
 		if targetSyntax == "yml" {
-			parseYamlLine(row)
+			err = goini.parseYamlLine(row)
 		} else if targetSyntax == "toml" {
-			parseTomlLine(row)
+			err = goini.parseTomlLine(row)
 		} else {
 			rowStr := string(row)
-			parseLine(rowStr)
+			err = goini.parseLine(rowStr)
 		}
 
+		if err != nil {
+			return err
+		}
 	}
 
-	property = nil
-	lineNode = LineNode{}
-
 	return nil
 }
 
+/**
+ * 读取一行数据，超出缓冲区的长行会被拼接完整
+ * @param br *bufio.Reader
+ * @return []byte, error
+ */
+func readLine(br *bufio.Reader) ([]byte, error) {
+	row, isPrefix, err := br.ReadLine()
+	if !isPrefix || err != nil {
+		return row, err
+	}
+
+	line := append([]byte{}, row...)
+	for isPrefix && err == nil {
+		row, isPrefix, err = br.ReadLine()
+		line = append(line, row...)
+	}
+
+	return line, nil
+}
+
 /**
  * 解析每行数据
  * @param rowStr string 每行数据
+ * @return error 解析失败时返回 *ParseError
  */
-func parseLine(rowStr string) {
+func (goini *Goini) parseLine(rowStr string) error {
+	rawStr := rowStr
 
 	// 去除空白，空格等字符
 	rowStr = strings.TrimSpace(rowStr)
 
 	// 解析注释行
 	if strings.Index(rowStr, "#") == 0 || strings.Index(rowStr, ";") == 0 {
-		return
+		return nil
 	}
 
 	//匹配节
@@ -486,23 +1146,35 @@ func parseLine(rowStr string) {
 
 		rowStr = rxFirstSection.FindString(rowStr)
 
-		parseSection(rowStr)
+		goini.parseSection(rowStr)
+
+		// 节名未闭合
+	} else if strings.HasPrefix(rowStr, "[") {
+		return goini.parseError(rawStr, ErrSectionNotClosed)
+
+		// 引入其他文件
+	} else if rxInclude.MatchString(rowStr) {
+		return goini.parseError(rawStr, goini.parseInclude(parsNodeValue(rowStr[strings.Index(rowStr, "=")+1:])))
 
 		//匹配到节点
 	} else if rxNode.MatchString(rowStr) {
-		parseProperty(rowStr)
+		return goini.parseError(rawStr, goini.parseProperty(rowStr))
 	}
+
+	return nil
 }
 
 /**
  * 解析节
  * @param rowStr string
  */
-func parseSection(rowStr string) {
+func (goini *Goini) parseSection(rowStr string) {
+
+	sectionName := rxSection.ReplaceAllString(rowStr, "")
 
-	sectionName = rxSection.ReplaceAllString(rowStr, "")
+	goini.sectionName = sectionName
 
-	property = make(map[string]interface{}) // 重新初始化
+	goini.property = make(map[string]interface{}) // 重新初始化
 
 	pos := strings.IndexAny(sectionName, ":")
 
@@ -520,26 +1192,39 @@ func parseSection(rowStr string) {
 			return
 		}
 
-		// 存在节点直接返回
-		_, ok := sections[child]
+		goini.sectionName = child
+
+		// 存在节点则继续在该节上设置
+		_, ok := goini.sections[child]
 		if ok {
+			goini.property = goini.getSection(child)
+			goini.sections[child] = goini.property
 			return
 		}
 
 		//继承父节点
-		parentMap = GetSection(parent)
+		goini.property = goini.getSection(parent)
 
 		//设置当前节点
-		sections[child] = parentMap
+		goini.sections[child] = goini.property
+
+		// 记录继承关系及节自身的节点
+		goini.setParent(child, parent)
+		goini.own[child] = make(map[string]interface{})
+
+		// 继承父节点的来源
+		for key, origin := range goini.origins[parent] {
+			goini.setOrigin(child, key, origin)
+		}
 
 	} else {
 		// 存在节点直接返回
-		_, ok := sections[sectionName]
+		_, ok := goini.sections[sectionName]
 		if ok {
-			property = GetSection(sectionName)
+			goini.property = goini.getSection(sectionName)
 		}
 
-		sections[sectionName] = property
+		goini.sections[sectionName] = goini.property
 
 	}
 }
@@ -547,8 +1232,9 @@ func parseSection(rowStr string) {
 /**
  * 解析节点
  * @param rowStr string
+ * @return error
  */
-func parseProperty(rowStr string) {
+func (goini *Goini) parseProperty(rowStr string) error {
 
 	posEq := strings.IndexAny(rowStr, "=")
 
@@ -566,38 +1252,47 @@ func parseProperty(rowStr string) {
 
 		// 处理变量引用
 		if strings.HasPrefix(valueStr, "${") {
-			if parseVariate(keyName, valueStr) {
-				return
+			if goini.parseVariate(keyName, valueStr) {
+				return nil
 			}
 		}
 
 		// 处理数组
 		valueStrRow = strings.TrimSpace(valueStrRow)
 		if strings.HasPrefix(valueStrRow, "[") {
-			strArr := findSliceString(valueStrRow)
+			strArr, err := findSliceString(valueStrRow)
+			if err != nil {
+				return err
+			}
+
 			if len(strArr) > 0 {
-				retSlice := parseSliceRow(valueStrRow, 0)
+				retSlice, err := parseSliceRow(valueStrRow, 0)
+				if err != nil {
+					return err
+				}
 
 				//设置属性
-				setProperty(keyName, retSlice)
+				goini.setProperty(keyName, retSlice)
 				//设置值
 				//property[keyName] = retSlice
 
-				return
+				return nil
 			}
 		}
 
 		//设置属性
-		setProperty(keyName, valueStr)
+		goini.setProperty(keyName, valueStr)
 
 		//设置值
 		//property[keyName] = valueStr
 
 	}
+
+	return nil
 }
 
 // 解析变量
-func parseVariate(keyName, valueStr string) bool {
+func (goini *Goini) parseVariate(keyName, valueStr string) bool {
 	pos := strings.Index(valueStr, "}")
 	if pos+1 >= len(valueStr) {
 		varStr := rxVariate.FindString(valueStr)
@@ -605,17 +1300,29 @@ func parseVariate(keyName, valueStr string) bool {
 			// 取变量中的值${xxx.xx} => xxx.xx
 			quoteKey := varStr[2 : len(varStr)-1]
 
+			// 环境变量在取值时解析
+			if goini.isEnvVariate(quoteKey) {
+				return false
+			}
+
+			quoteKey, _, hasModifier := splitVariable(quoteKey)
+
 			var mixVal interface{}
 			colonPos := strings.Index(quoteKey, ":")
 
 			if colonPos > 0 {
 				// fmt.Println(quoteKey[colonPos+1:], quoteKey[:colonPos])
-				mixVal = getValBySection(quoteKey[colonPos+1:], quoteKey[:colonPos])
+				mixVal = goini.getValBySection(quoteKey[colonPos+1:], quoteKey[:colonPos])
 			} else {
-				mixVal = getValBySection(quoteKey, "")
+				mixVal = goini.getValBySection(quoteKey, "")
+			}
+
+			// 未取到值时保留原样，之后定义的节点可以在取值时解析，否则使用默认值
+			if hasModifier && (mixVal == nil || mixVal == "") {
+				return false
 			}
 
-			setProperty(keyName, mixVal)
+			goini.setProperty(keyName, mixVal)
 
 			// 设置值
 			//property[keyName] = mixVal
@@ -690,13 +1397,81 @@ func parsNodeValue(valueStr string) string {
 	return valueStr
 }
 
+// 以引号开头的字符串中，闭合引号之后的位置，与 parsNodeValue 一样不处理转义，未闭合时返回-1
+func quotedLen(valStr string) int {
+	if valStr == "" || (valStr[0] != '"' && valStr[0] != '\'') {
+		return -1
+	}
+
+	if pos := strings.IndexByte(valStr[1:], valStr[0]); pos != -1 {
+		return pos + 2
+	}
+
+	return -1
+}
+
+// 转义yml、toml双引号字符串中的字符，ini的引号中不支持转义
+func escapeString(valStr string) string {
+	return strings.NewReplacer("\\", "\\\\", "\"", "\\\"", "\n", "\\n", "\r", "\\r").Replace(valStr)
+}
+
+// 还原yml、toml双引号字符串中转义的字符，无法识别的转义保持原样
+func unescapeString(valStr string) string {
+	if !strings.Contains(valStr, "\\") {
+		return valStr
+	}
+
+	var buf strings.Builder
+	for i := 0; i < len(valStr); i++ {
+		if valStr[i] != '\\' || i+1 == len(valStr) {
+			buf.WriteByte(valStr[i])
+			continue
+		}
+
+		switch valStr[i+1] {
+		case 'n':
+			buf.WriteByte('\n')
+		case 'r':
+			buf.WriteByte('\r')
+		case 't':
+			buf.WriteByte('\t')
+		case '\\', '"':
+			buf.WriteByte(valStr[i+1])
+		default:
+			buf.WriteString(valStr[i : i+2])
+		}
+
+		i++
+	}
+
+	return buf.String()
+}
+
 /**
  * 设置值
  * @param keyName string 节点名
  * @param valueStr string 节点值
  */
-func setProperty(keyName string, valueStr interface{}) {
+func (goini *Goini) setProperty(keyName string, valueStr interface{}) {
+
+	// 记录来源
+	goini.setOrigin(goini.sectionName, keyName, goini.parseName)
+
+	// 记录必填变量，Set 等解析之外的调用不检查
+	if valStr, ok := valueStr.(string); ok && goini.lineNum > 0 {
+		goini.recordRequired(valStr)
+	}
 
+	putProperty(goini.property, keyName, valueStr)
+
+	// 继承父节的节另外记录自身的节点，合并多个文件后重新继承
+	if own, ok := goini.own[goini.sectionName]; ok {
+		putProperty(own, keyName, valueStr)
+	}
+}
+
+// 设置节点的值，含有.的节点同时按层级设置
+func putProperty(property map[string]interface{}, keyName string, valueStr interface{}) {
 	if strings.IndexAny(keyName, ".") != -1 {
 
 		keyArr := strings.Split(keyName, ".")
@@ -712,11 +1487,11 @@ func setProperty(keyName string, valueStr interface{}) {
 		//	} else {
 		//		currentStr := prevStr + "." + keyArr[i]
 		//
-		//		setKeyVal(prevStr, currentStr, property[currentStr])
+		//		setKeyVal(prevStr, currentStr, goini.property[currentStr])
 		//	}
 		//
 		//}
-		// property[keyName] = valueStr
+		// goini.property[keyName] = valueStr
 
 		ret := setKeyVal(keyArr, valueStr, property[keyArr[0]], 1)
 		if mp, ok := ret.(map[string]interface{}); ok {
@@ -779,37 +1554,19 @@ func setKeyVal(keyArr []string, lineVal interface{}, obj interface{}, depth int)
 	return obj
 }
 
-/**
- * 设置链式key值
- * @param prevStr string 父节点名
- * @param currentStr string 当前节点名
- * @param valueStr interface{} 混合类型的值
- */
-func setKeyValOld(prevStr string, currentStr string, valueStr interface{}) {
-
-	tempObj := property[prevStr]
-
-	if tempObj == nil {
-
-		valMap := make(map[string]interface{})
-
-		valMap[currentStr] = valueStr
-
-		property[prevStr] = valMap
-
-	} else {
-
-		jsonStr, _ := json.Marshal(tempObj)
-
-		tempMap := make(map[string]interface{})
-
-		json.Unmarshal([]byte(jsonStr), &tempMap)
-
-		tempMap[currentStr] = valueStr
-
-		property[prevStr] = tempMap
+// GetSection 获取最近一次通过 New 或 Load 加载的配置中的节内容，未加载时返回空map
+//
+// Deprecated: 使用 (*Goini).GetSection
+func GetSection(sectionKey string) map[string]interface{} {
+	lastLoaded.Lock()
+	config := lastLoaded.config
+	lastLoaded.Unlock()
 
+	if config == nil {
+		return make(map[string]interface{})
 	}
+
+	return config.GetSection(sectionKey)
 }
 
 /**
@@ -817,18 +1574,21 @@ func setKeyValOld(prevStr string, currentStr string, valueStr interface{}) {
  * @param rowStr string
  * @return map[string]interface{}
  */
-func GetSection(sectionKey string) map[string]interface{} {
+func (goini *Goini) getSection(sectionKey string) map[string]interface{} {
 	// 使用默认值
 	if sectionKey == "" {
 		sectionKey = defaultName
 	}
 
-	property = make(map[string]interface{})
+	property := make(map[string]interface{})
 
-	jsonStr, err := json.Marshal(sections[sectionKey])
+	if _, ok := goini.sections[sectionKey]; !ok {
+		return property
+	}
 
+	jsonStr, err := json.Marshal(goini.sections[sectionKey])
 	if err != nil {
-		sections[sectionKey] = property
+		return property
 	}
 
 	json.Unmarshal([]byte(jsonStr), &property)
@@ -836,24 +1596,6 @@ func GetSection(sectionKey string) map[string]interface{} {
 	return property
 }
 
-// 获取节点内容
-func getValBySection(key string, section string) interface{} {
-	if section == "" || section == "<nil>" {
-		section = defaultName
-	}
-
-	if tempRet, ok := sections[section].(map[string]interface{}); ok {
-		if mp, ok := tempRet[key]; ok {
-			return mp
-		} else {
-			keyArr := strings.Split(key, ".")
-			return getMapVal(keyArr, tempRet, 0)
-		}
-	}
-
-	return nil
-}
-
 func getMapVal(keyArr []string, nextMap interface{}, depth int) interface{} {
 	if nextMap == nil {
 		return nextMap
@@ -883,3 +1625,32 @@ func getMapVal(keyArr []string, nextMap interface{}, depth int) interface{} {
 
 	return ret
 }
+
+// 深拷贝map及切片类型的值
+func copyValue(val interface{}) interface{} {
+	switch v := val.(type) {
+	case map[string]interface{}:
+		mp := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			mp[k] = copyValue(item)
+		}
+
+		return mp
+	case []map[string]interface{}:
+		arr := make([]map[string]interface{}, len(v))
+		for i, item := range v {
+			arr[i], _ = copyValue(item).(map[string]interface{})
+		}
+
+		return arr
+	case []interface{}:
+		arr := make([]interface{}, len(v))
+		for i, item := range v {
+			arr[i] = copyValue(item)
+		}
+
+		return arr
+	}
+
+	return val
+}
@@ -1,10 +1,20 @@
 package goini
 
 import (
+	"errors"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"testing/fstest"
+	"time"
 )
 
-var config = Load("app.ini")
+var config = Load("app.ini", "")
 
 func TestGoini_Get(t *testing.T) {
 	testCases := []struct {
@@ -63,3 +73,626 @@ func TestGoini_GetBySection(t *testing.T) {
 		}
 	}
 }
+
+func TestGoini_Independent(t *testing.T) {
+	dir := t.TempDir()
+
+	iniPath := filepath.Join(dir, "plugin.ini")
+	ioutil.WriteFile(iniPath, []byte("host = 10.0.0.1\n[db]\nport = 5432\n"), 0644)
+
+	tomlPath := filepath.Join(dir, "plugin.toml")
+	ioutil.WriteFile(tomlPath, []byte("[[servers]]\nname = \"a\"\n[[servers]]\nname = \"b\"\n"), 0644)
+
+	plugin := Load(iniPath, "")
+	plugins := Load(tomlPath, "toml")
+
+	if ret := config.Get("host"); ret != "127.0.0.1" {
+		t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, "127.0.0.1")
+	}
+
+	if ret := config.Get("port", "db"); ret != "3306" {
+		t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, "3306")
+	}
+
+	if ret := plugin.Get("port", "db"); ret != "5432" {
+		t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, "5432")
+	}
+
+	if ret := plugin.Get("env"); ret != nil {
+		t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, nil)
+	}
+
+	if servers, ok := plugins.Get("servers").([]map[string]interface{}); !ok || len(servers) != 2 {
+		t.Errorf("Goini: Not as expected ret=%v", plugins.Get("servers"))
+	}
+
+	plugin.Set("host", "10.0.0.2")
+	if ret := config.Get("host"); ret != "127.0.0.1" {
+		t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, "127.0.0.1")
+	}
+}
+
+func TestGoini_Deprecated(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "legacy.ini")
+	ioutil.WriteFile(path, []byte("[db]\nport = 5432\n"), 0644)
+
+	Load(path, "")
+
+	if ret := GetSection("db")["port"]; ret != "5432" {
+		t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, "5432")
+	}
+
+	testCases := []struct {
+		Key    string
+		Expect string
+	}{
+		{Key: "legacy", Expect: "1"},
+		{Key: "legacy", Expect: "2"},
+		{Key: "legacy.items", Expect: "2.1"},
+		{Key: "legacy", Expect: "3.0"},
+	}
+
+	for _, v := range testCases {
+		JoinIndex(v.Key)
+
+		if ret := IdxMap["legacy"]; ret != v.Expect {
+			t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, v.Expect)
+		}
+	}
+}
+
+func TestNewE(t *testing.T) {
+	// 未注册命令行参数时使用执行文件所在目录下的 application.ini，测试程序的目录中不存在
+	if _, err := NewE(""); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Goini: Not as expected err=%v", err)
+	}
+
+	defer func() {
+		if err := recover(); err == nil {
+			t.Errorf("Goini: Not as expected err=%v", err)
+		}
+	}()
+
+	New("")
+}
+
+func TestGoini_Concurrent(t *testing.T) {
+	type dbObj struct {
+		Driver string `json:"driver"`
+		Port   int64  `json:"port"`
+	}
+
+	conf := Load("app.ini", "")
+	conf.Set("db.driver", "mysql", "database")
+	conf.Set("db.port", "3306", "database")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(3)
+
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if ret := conf.Get("driver", "db"); ret != "mysql" {
+					t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, "mysql")
+				}
+				conf.GetSection("db")
+			}
+		}()
+
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				var obj dbObj
+				conf.GetStruct("db", &obj, "database")
+				if obj.Driver != "mysql" {
+					t.Errorf("Goini: Not as expected ret=%v, expect=%v", obj.Driver, "mysql")
+				}
+			}
+		}()
+
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				conf.Set("counter", j, "db")
+				conf.Set("db.user", i, "database")
+			}
+		}(i)
+	}
+
+	wg.Wait()
+}
+
+func TestLoadFile_Error(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := LoadFile(filepath.Join(dir, "missing.ini"), ""); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Goini: Not as expected err=%v", err)
+	}
+
+	testCases := []struct {
+		Name    string
+		Syntax  string
+		Content string
+		Line    int
+		Column  int
+		Err     error
+	}{
+		{
+			Name:    "app.ini",
+			Content: "env = test\n[db]\nhosts = [a, [b, c]\n",
+			Line:    3,
+			Column:  9,
+			Err:     ErrSymbolMismatch,
+		},
+		{
+			Name:    "app.ini",
+			Content: "env = test\n[db\n",
+			Line:    2,
+			Column:  1,
+			Err:     ErrSectionNotClosed,
+		},
+		{
+			Name:    "app.yml",
+			Syntax:  "yml",
+			Content: "app:\n  hosts: [a, b]]\n",
+			Line:    2,
+			Column:  16,
+			Err:     ErrSymbolMismatch,
+		},
+		{
+			Name:    "app.toml",
+			Syntax:  "toml",
+			Content: "[app]\nopts = { a = 1, b = { c = 2 }\n",
+			Line:    2,
+			Column:  21,
+			Err:     ErrSymbolMismatch,
+		},
+		{
+			Name:    "app.ini",
+			Content: "env = test\n[db]\ndsn = mysql://${db:user|?db user is required}@localhost\n",
+			Line:    3,
+			Column:  15,
+			Err:     ErrMissingVariable,
+		},
+		{
+			Name:    "app.yml",
+			Syntax:  "yml",
+			Content: "db:\n  password: ${db.password|?}\n",
+			Line:    2,
+			Column:  13,
+			Err:     ErrMissingVariable,
+		},
+	}
+
+	for _, v := range testCases {
+		path := filepath.Join(dir, v.Name)
+		ioutil.WriteFile(path, []byte(v.Content), 0644)
+
+		_, err := LoadFile(path, v.Syntax)
+
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) {
+			t.Errorf("Goini: Not as expected err=%v", err)
+			continue
+		}
+
+		if parseErr.File != path || parseErr.Line != v.Line || parseErr.Column != v.Column || !errors.Is(err, v.Err) {
+			t.Errorf("Goini: Not as expected err=%v, expect=%v:%v:%v", err, v.Line, v.Column, v.Err)
+		}
+	}
+}
+
+func TestLoadReader(t *testing.T) {
+	fromString, err := LoadString("env = dev\n[db]\nport = 3306\n", "ini")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fromBytes, err := LoadBytes([]byte("db:\n  port: 3307\n"), "yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fsys := fstest.MapFS{
+		"conf/app.toml": &fstest.MapFile{Data: []byte("[db]\nport = 3308\n")},
+	}
+
+	fromFS, err := LoadFS(fsys, "conf/app.toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		Config *Goini
+		Key    string
+		Args   []interface{}
+		Expect int64
+	}{
+		{Config: fromString, Key: "port", Args: []interface{}{"db"}, Expect: 3306},
+		{Config: fromBytes, Key: "db.port", Expect: 3307},
+		{Config: fromFS, Key: "db.port", Expect: 3308},
+	}
+
+	for _, v := range testCases {
+		if ret := v.Config.GetInt(v.Key, v.Args...); ret != v.Expect {
+			t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, v.Expect)
+		}
+	}
+
+	if _, err := LoadFS(fsys, "conf/missing.ini"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Goini: Not as expected err=%v", err)
+	}
+}
+
+func TestParseValue_Backslash(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.ini")
+	ioutil.WriteFile(path, []byte("dir = \"C:\\new\\table\"\nsingle = 'C:\\new\\table'\nplain = C:\\new\\table\nquote = \"a\\\"\n"), 0644)
+
+	config, err := LoadFile(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// ini的引号中不处理转义，与原有的读取方式一致
+	testCases := []struct {
+		Key    string
+		Expect string
+	}{
+		{Key: "dir", Expect: `C:\new\table`},
+		{Key: "single", Expect: `C:\new\table`},
+		{Key: "plain", Expect: `C:\new\table`},
+		{Key: "quote", Expect: `a\`},
+	}
+
+	for _, v := range testCases {
+		if ret := config.GetString(v.Key); ret != v.Expect {
+			t.Errorf("Goini: Not as expected ret=%q, expect=%q", ret, v.Expect)
+		}
+	}
+
+	// 写回后保持不变
+	config.Set("name", "goini")
+
+	buf := &strings.Builder{}
+	config.WriteTo(buf)
+
+	if !strings.Contains(buf.String(), "dir = \"C:\\new\\table\"\n") {
+		t.Errorf("Goini: Not as expected ret=%q", buf.String())
+	}
+}
+
+func TestVariableModifier(t *testing.T) {
+	t.Setenv("GOINI_HOME", "/home/goini")
+
+	content := "host = ${db:host|127.0.0.1}\nport = ${db:port|3306}\nuser = ${db:user|root}\n" +
+		"home = ${env:GOINI_HOME|/tmp}/data\ncache = ${env:GOINI_MISSING|/tmp}/cache\n" +
+		"name = ${db:name|?name is required}\nurl = ${db:host|localhost}:${db:port|3306}\n" +
+		"[db]\nport = 3307\nname = app\n"
+
+	config, err := LoadString(content, "ini", WithEnvExpansion(AnyEnv))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		Key    string
+		Expect string
+	}{
+		{Key: "host", Expect: "127.0.0.1"},
+		{Key: "port", Expect: "3307"},
+		{Key: "user", Expect: "root"},
+		{Key: "home", Expect: "/home/goini/data"},
+		{Key: "cache", Expect: "/tmp/cache"},
+		{Key: "name", Expect: "app"},
+		{Key: "url", Expect: "localhost:3307"},
+	}
+
+	for _, v := range testCases {
+		if ret := config.GetString(v.Key); ret != v.Expect {
+			t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, v.Expect)
+		}
+	}
+
+	// yml、toml 中的默认值
+	sources := []struct {
+		Syntax string
+		Data   string
+	}{
+		{Syntax: "yml", Data: "db:\n  port: ${db.missing|3306}\n  url: ${db.host|localhost}:${db.missing|3306}\n"},
+		{Syntax: "toml", Data: "[db]\nport = \"${db.missing|3306}\"\nurl = \"${db.host|localhost}:${db.missing|3306}\"\n"},
+	}
+
+	for _, source := range sources {
+		config, err := LoadString(source.Data, source.Syntax)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if ret := config.GetInt("db.port"); ret != 3306 {
+			t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, 3306)
+		}
+
+		if ret := config.GetString("db.url"); ret != "localhost:3306" {
+			t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, "localhost:3306")
+		}
+	}
+
+	// 必填的变量缺失时加载失败
+	_, err = LoadString("dsn = ${env:GOINI_MISSING|?GOINI_MISSING must be set}\n", "ini")
+	if !errors.Is(err, ErrMissingVariable) || !strings.Contains(err.Error(), "GOINI_MISSING must be set") {
+		t.Errorf("Goini: Not as expected err=%v", err)
+	}
+}
+
+func TestGetE(t *testing.T) {
+	config, err := LoadString("port = 80x\nempty =\nname = goini\nratio = 0.5\ndebug = yes\n"+
+		"[db]\nport = 3306\nhosts = [a, b]\nproxy = ${port}\n", "ini")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		Get     func() (interface{}, error)
+		Expect  interface{}
+		Err     error
+		Section string
+		Value   string
+	}{
+		{Get: func() (interface{}, error) { return config.GetIntE("port", "db") }, Expect: int64(3306)},
+		{Get: func() (interface{}, error) { return config.GetIntE("port") }, Expect: int64(0), Err: ErrInvalidValue, Section: "default", Value: "80x"},
+		{Get: func() (interface{}, error) { return config.GetIntE("proxy", "db") }, Expect: int64(0), Err: ErrInvalidValue, Section: "db", Value: "80x"},
+		{Get: func() (interface{}, error) { return config.GetIntE("missing", "db") }, Expect: int64(0), Err: ErrNotFound, Section: "db"},
+		{Get: func() (interface{}, error) { return config.GetIntE("missing", "db", 8080) }, Expect: int64(8080)},
+		{Get: func() (interface{}, error) { return config.GetIntE("empty") }, Expect: int64(0), Err: ErrInvalidValue, Section: "default"},
+		{Get: func() (interface{}, error) { return config.GetFloatE("ratio") }, Expect: 0.5},
+		{Get: func() (interface{}, error) { return config.GetFloatE("name") }, Expect: float64(0), Err: ErrInvalidValue, Section: "default", Value: "goini"},
+		{Get: func() (interface{}, error) { return config.GetBoolE("debug") }, Expect: true},
+		{Get: func() (interface{}, error) { return config.GetBoolE("name") }, Expect: false, Err: ErrInvalidValue, Section: "default", Value: "goini"},
+		{Get: func() (interface{}, error) { return config.GetStringE("name") }, Expect: "goini"},
+		{Get: func() (interface{}, error) { return config.GetStringE("empty") }, Expect: ""},
+		{Get: func() (interface{}, error) { return config.GetStringE("hosts", "db") }, Expect: "", Err: ErrInvalidValue, Section: "db", Value: `["a","b"]`},
+		{Get: func() (interface{}, error) { return config.GetStringE("missing") }, Expect: "", Err: ErrNotFound, Section: "default"},
+	}
+
+	for _, v := range testCases {
+		ret, err := v.Get()
+		if ret != v.Expect || !errors.Is(err, v.Err) {
+			t.Errorf("Goini: Not as expected ret=%v, err=%v, expect=%v", ret, err, v.Expect)
+			continue
+		}
+
+		var notFoundErr *NotFoundError
+		if errors.As(err, &notFoundErr) && notFoundErr.Section != v.Section {
+			t.Errorf("Goini: Not as expected ret=%v, expect=%v", notFoundErr.Section, v.Section)
+		}
+
+		var valueErr *ValueError
+		if errors.As(err, &valueErr) && (valueErr.Section != v.Section || valueErr.Value != v.Value) {
+			t.Errorf("Goini: Not as expected ret=%v, expect=%v %v", valueErr, v.Section, v.Value)
+		}
+	}
+
+	if ret, ok := config.Lookup("empty", ""); !ok || ret != "" {
+		t.Errorf("Goini: Not as expected ret=%v, found=%v", ret, ok)
+	}
+
+	if ret, ok := config.Lookup("port", "db"); !ok || ret != "3306" {
+		t.Errorf("Goini: Not as expected ret=%v, found=%v", ret, ok)
+	}
+
+	if ret, ok := config.Lookup("missing", "db"); ok || ret != nil {
+		t.Errorf("Goini: Not as expected ret=%v, found=%v", ret, ok)
+	}
+}
+
+func TestParseInteger(t *testing.T) {
+	config, err := LoadString("[app]\nnums.id = 9007199254740993\nnums.max = 18446744073709551615\nnums.hex = 0x1F\n"+
+		"nums.oct = 0o755\nnums.bin = 0b1010\nnums.sep = 1_000_000\nnums.zero = 0012\nnums.neg = -42\n"+
+		"nums.frac = 3.7\nnums.small = 300\n", "ini")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		Key    string
+		Expect int64
+		Err    error
+	}{
+		{Key: "id", Expect: 9007199254740993},
+		{Key: "hex", Expect: 31},
+		{Key: "oct", Expect: 493},
+		{Key: "bin", Expect: 10},
+		{Key: "sep", Expect: 1000000},
+		{Key: "zero", Expect: 12},
+		{Key: "neg", Expect: -42},
+		{Key: "frac", Err: ErrInvalidValue},
+		{Key: "max", Err: ErrInvalidValue},
+	}
+
+	for _, v := range testCases {
+		ret, err := config.GetIntE("nums."+v.Key, "app")
+		if ret != v.Expect || !errors.Is(err, v.Err) {
+			t.Errorf("Goini: Not as expected ret=%v, err=%v, expect=%v", ret, err, v.Expect)
+		}
+	}
+
+	nums := struct {
+		ID    int64   `json:"id"`
+		Max   uint64  `json:"max"`
+		Hex   int     `json:"hex"`
+		Oct   uint32  `json:"oct"`
+		Bin   *int8   `json:"bin"`
+		Sep   int     `json:"sep"`
+		Zero  uint16  `json:"zero"`
+		Neg   int16   `json:"neg"`
+		Ratio float32 `json:"frac"`
+	}{}
+
+	if err := config.GetStructE("nums", &nums, "app"); err != nil {
+		t.Fatal(err)
+	}
+
+	if nums.ID != 9007199254740993 || nums.Max != 18446744073709551615 || nums.Hex != 31 || nums.Oct != 493 ||
+		*nums.Bin != 10 || nums.Sep != 1000000 || nums.Zero != 12 || nums.Neg != -42 || nums.Ratio != 3.7 {
+		t.Errorf("Goini: Not as expected ret=%+v", nums)
+	}
+
+	// 超出范围及小数
+	invalidCases := []struct {
+		Target interface{}
+		Key    string
+		Value  string
+		Err    error
+	}{
+		{Target: &struct {
+			Small int8 `json:"small"`
+		}{}, Key: "nums.small", Value: "300", Err: strconv.ErrRange},
+		{Target: &struct {
+			Neg uint16 `json:"neg"`
+		}{}, Key: "nums.neg", Value: "-42", Err: strconv.ErrSyntax},
+		{Target: &struct {
+			Frac int `json:"frac"`
+		}{}, Key: "nums.frac", Value: "3.7", Err: strconv.ErrSyntax},
+	}
+
+	for _, v := range invalidCases {
+		err := config.GetStructE("nums", v.Target, "app")
+
+		var valueErr *ValueError
+		if !errors.As(err, &valueErr) || valueErr.Key != v.Key || valueErr.Section != "app" || valueErr.Value != v.Value || !errors.Is(err, v.Err) {
+			t.Errorf("Goini: Not as expected err=%v", err)
+		}
+	}
+
+	if err := config.GetStructE("missing", &nums, "app"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Goini: Not as expected err=%v", err)
+	}
+
+	// toml 中的分隔符
+	config, err = LoadString("[limits]\nmax_conn = 1_000_000\nmask = 0o755\n", "toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	limits := struct {
+		MaxConn int    `json:"max_conn"`
+		Mask    uint32 `json:"mask"`
+	}{}
+
+	config.GetStruct("limits", &limits)
+	if limits.MaxConn != 1000000 || limits.Mask != 493 {
+		t.Errorf("Goini: Not as expected ret=%+v", limits)
+	}
+}
+
+func TestGetDuration(t *testing.T) {
+	config, err := LoadString("[server]\nhttp.read_timeout = 5s\nhttp.idle_timeout = 1h30m\nhttp.max_body = 10MB\n"+
+		"http.buffer = 64KiB\nhttp.retries = 1s, 2s, 500ms\nhttp.bad_timeout = 5\nhttp.bad_size = 10XB\n", "ini")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ret := config.GetDuration("http.read_timeout", "server"); ret != 5*time.Second {
+		t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, 5*time.Second)
+	}
+
+	if ret := config.GetDuration("http.missing", "server", time.Minute); ret != time.Minute {
+		t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, time.Minute)
+	}
+
+	if _, err := config.GetDurationE("http.bad_timeout", "server"); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("Goini: Not as expected err=%v", err)
+	}
+
+	if ret := config.GetBytes("http.max_body", "server"); ret != 10*MB {
+		t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, 10*MB)
+	}
+
+	if _, err := config.GetBytesE("http.bad_size", "server"); !errors.Is(err, ErrInvalidValue) || !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("Goini: Not as expected err=%v", err)
+	}
+
+	type httpConfig struct {
+		ReadTimeout time.Duration   `json:"read_timeout"`
+		IdleTimeout *time.Duration  `json:"idle_timeout"`
+		MaxBody     ByteSize        `json:"max_body"`
+		Buffer      *ByteSize       `json:"buffer"`
+		Retries     []time.Duration `json:"retries" ini:"seq=, "`
+	}
+
+	var http httpConfig
+	config.GetStruct("http", &http, "server")
+
+	if http.ReadTimeout != 5*time.Second || *http.IdleTimeout != 90*time.Minute || http.MaxBody != 10*MB || *http.Buffer != 64*KiB {
+		t.Errorf("Goini: Not as expected ret=%+v", http)
+	}
+
+	if len(http.Retries) != 3 || http.Retries[2] != 500*time.Millisecond {
+		t.Errorf("Goini: Not as expected ret=%v", http.Retries)
+	}
+
+	bad := struct {
+		Timeout time.Duration `json:"bad_timeout"`
+	}{}
+
+	if err := config.GetStructE("http", &bad, "server"); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("Goini: Not as expected err=%v", err)
+	}
+
+	// 编码后可以重新解析
+	server := struct {
+		HTTP httpConfig `json:"http"`
+	}{http}
+
+	data, err := Marshal(struct {
+		Server interface{} `json:"server"`
+	}{server}, "ini")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config, err = LoadBytes(data, "ini")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded httpConfig
+	config.GetStruct("http", &decoded, "server")
+	if decoded.ReadTimeout != http.ReadTimeout || decoded.MaxBody != http.MaxBody {
+		t.Errorf("Goini: Not as expected ret=%+v, data=%s", decoded, data)
+	}
+}
+
+func TestGetStruct_Time(t *testing.T) {
+	config, err := LoadString("[app]\nrelease.date = 2020-01-02\nrelease.created = 2020-01-02 03:04:05\n"+
+		"release.updated = 2020-01-02 03:04:05\nrelease.history = 2020-01-01 00:00:00, 2020-01-02 00:00:00\n", "ini")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type release struct {
+		Date    *time.Time  `json:"date" ini:"tpl=2006-01-02"`
+		Created time.Time   `json:"created"`
+		Updated *time.Time  `json:"updated"`
+		History []time.Time `json:"history" ini:"seq=, "`
+	}
+
+	var ret release
+	if err := config.GetStructE("release", &ret, "app"); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := time.Date(2020, 1, 2, 3, 4, 5, 0, time.Local)
+	if ret.Date == nil || !ret.Date.Equal(time.Date(2020, 1, 2, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("Goini: Not as expected ret=%v", ret.Date)
+	}
+
+	if !ret.Created.Equal(expect) || ret.Updated == nil || !ret.Updated.Equal(expect) {
+		t.Errorf("Goini: Not as expected ret=%+v, expect=%v", ret, expect)
+	}
+
+	if len(ret.History) != 2 || !ret.History[1].Equal(time.Date(2020, 1, 2, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("Goini: Not as expected ret=%v", ret.History)
+	}
+}
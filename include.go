@@ -0,0 +1,159 @@
+package goini
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+/**
+ * 解析 include = xxx.ini 引入的文件，支持通配符
+ * 相对路径以当前文件所在目录为准，引入文件中的节点从当前节开始解析
+ * @param pattern string 文件路径或通配符
+ * @return error
+ */
+func (goini *Goini) parseInclude(pattern string) error {
+	if pattern == "" {
+		return nil
+	}
+
+	pattern = goini.resolvePath(pattern)
+
+	files := []string{pattern}
+	if strings.ContainsAny(pattern, "*?[") {
+		matches, err := goini.glob(pattern)
+		if err != nil {
+			return err
+		}
+
+		sort.Strings(matches)
+		files = matches
+
+		// 监听目录，以便发现新增的文件
+		goini.files = append(goini.files, goini.dir(pattern))
+	}
+
+	for _, file := range files {
+		if err := goini.includeFile(file); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// 解析单个引入文件
+func (goini *Goini) includeFile(file string) error {
+	// 检测循环引入
+	chain := append(append([]string{}, goini.includes...), goini.parseName)
+	for idx, name := range chain {
+		if goini.samePath(name, file) {
+			return fmt.Errorf("%w: %s", ErrIncludeCycle, strings.Join(append(chain[idx:], file), " -> "))
+		}
+	}
+
+	fp, err := goini.openFile(file)
+	if err != nil {
+		return err
+	}
+
+	defer fp.Close()
+
+	goini.files = append(goini.files, file)
+
+	// 保存当前解析状态，引入的文件不改变当前节
+	parseName, lineNum := goini.parseName, goini.lineNum
+	lineNode, tomlLineNode := goini.lineNode, goini.tomlLineNode
+	sectionName := goini.sectionName
+
+	goini.includes = chain
+	goini.lineNode = LineNode{}
+	goini.tomlLineNode = TomlLineNode{
+		KeyName: defaultName,
+	}
+
+	err = goini.parseLines(fp, file, "")
+
+	// 恢复解析状态
+	goini.includes = chain[:len(chain)-1]
+	goini.parseName, goini.lineNum = parseName, lineNum
+	goini.lineNode, goini.tomlLineNode = lineNode, tomlLineNode
+	goini.sectionName = sectionName
+
+	// 引入的文件可能重新打开当前节并替换节的map，重新取当前节的map
+	goini.property, _ = goini.sections[sectionName].(map[string]interface{})
+	if goini.property == nil {
+		goini.property = make(map[string]interface{})
+		goini.sections[sectionName] = goini.property
+	}
+
+	return err
+}
+
+// 打开文件
+func (goini *Goini) openFile(name string) (io.ReadCloser, error) {
+	if goini.fsys != nil {
+		return goini.fsys.Open(name)
+	}
+
+	return os.Open(name)
+}
+
+// 查找匹配的文件
+func (goini *Goini) glob(pattern string) ([]string, error) {
+	if goini.fsys != nil {
+		return fs.Glob(goini.fsys, pattern)
+	}
+
+	return filepath.Glob(pattern)
+}
+
+// 以当前解析的文件所在目录为准，解析相对路径
+func (goini *Goini) resolvePath(name string) string {
+	if goini.fsys != nil {
+		if path.IsAbs(name) {
+			return path.Clean(strings.TrimPrefix(name, "/"))
+		}
+
+		return path.Join(path.Dir(goini.parseName), name)
+	}
+
+	if filepath.IsAbs(name) {
+		return filepath.Clean(name)
+	}
+
+	return filepath.Join(filepath.Dir(goini.parseName), name)
+}
+
+// 获取所在目录
+func (goini *Goini) dir(name string) string {
+	if goini.fsys != nil {
+		return path.Dir(name)
+	}
+
+	return filepath.Dir(name)
+}
+
+// 是否是同一个文件
+func (goini *Goini) samePath(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+
+	if goini.fsys != nil {
+		return path.Clean(a) == path.Clean(b)
+	}
+
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	if errA != nil || errB != nil {
+		return filepath.Clean(a) == filepath.Clean(b)
+	}
+
+	return absA == absB
+}
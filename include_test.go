@@ -0,0 +1,105 @@
+package goini
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestInclude(t *testing.T) {
+	dir := t.TempDir()
+	os.Mkdir(filepath.Join(dir, "conf.d"), 0755)
+
+	ioutil.WriteFile(filepath.Join(dir, "base.ini"), []byte("env = base\n[database]\ndb.host = 127.0.0.1\ndb.port = 3306\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "conf.d", "10-db.ini"), []byte("[database]\ndb.port = 3307\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "conf.d", "20-cache.ini"), []byte("[cache]\nsize = 10\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "app.ini"), []byte("include = base.ini\ninclude = conf.d/*.ini\n[redis:database]\ndb.port = 6379\n"), 0644)
+
+	config, err := LoadFile(filepath.Join(dir, "app.ini"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		Key     string
+		Section string
+		Expect  string
+	}{
+		{Key: "env", Section: "default", Expect: "base"},
+		{Key: "db.port", Section: "database", Expect: "3307"},
+		{Key: "size", Section: "cache", Expect: "10"},
+		{Key: "db.host", Section: "redis", Expect: "127.0.0.1"},
+		{Key: "db.port", Section: "redis", Expect: "6379"},
+	}
+
+	for _, v := range testCases {
+		if ret := config.GetString(v.Key, v.Section); ret != v.Expect {
+			t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, v.Expect)
+		}
+	}
+
+	if ret := config.Source("db.host", "redis"); ret != filepath.Join(dir, "base.ini") {
+		t.Errorf("Goini: Not as expected source=%v", ret)
+	}
+}
+
+func TestInclude_KeepSection(t *testing.T) {
+	fsys := fstest.MapFS{
+		"app.ini":      &fstest.MapFile{Data: []byte("[main]\ninclude = conf.d/*.ini\nname = app\n")},
+		"conf.d/a.ini": &fstest.MapFile{Data: []byte("[db]\nhost = 127.0.0.1\n")},
+		"main.ini":     &fstest.MapFile{Data: []byte("[main]\nbefore = 1\ninclude = x.ini\nname = app\n")},
+		"x.ini":        &fstest.MapFile{Data: []byte("[main]\nfoo = 1\n")},
+	}
+
+	config, err := LoadFS(fsys, "app.ini")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		Key     string
+		Section string
+		Expect  string
+	}{
+		{Key: "name", Section: "main", Expect: "app"},
+		{Key: "host", Section: "db", Expect: "127.0.0.1"},
+		{Key: "name", Section: "db", Expect: ""},
+	}
+
+	for _, v := range testCases {
+		if ret := config.GetString(v.Key, v.Section); ret != v.Expect {
+			t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, v.Expect)
+		}
+	}
+
+	// 引入的文件重新打开当前节，之后的节点仍在该节中
+	config, err = LoadFS(fsys, "main.ini")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	section := config.GetSection("main")
+	if section["before"] != "1" || section["foo"] != "1" || section["name"] != "app" {
+		t.Errorf("Goini: Not as expected ret=%v", section)
+	}
+}
+
+func TestInclude_Cycle(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.ini":     &fstest.MapFile{Data: []byte("include = sub/b.ini\n")},
+		"sub/b.ini": &fstest.MapFile{Data: []byte("key = b\ninclude = ../a.ini\n")},
+	}
+
+	_, err := LoadFS(fsys, "a.ini")
+	if !errors.Is(err, ErrIncludeCycle) {
+		t.Fatalf("Goini: Not as expected err=%v", err)
+	}
+
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.File != "sub/b.ini" || parseErr.Line != 2 {
+		t.Errorf("Goini: Not as expected err=%v", err)
+	}
+}
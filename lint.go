@@ -0,0 +1,251 @@
+package goini
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+)
+
+// 诊断级别
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Diagnostic 配置文件中的问题，行号及列号从1开始
+type Diagnostic struct {
+	File     string
+	Line     int
+	Column   int
+	Severity string
+	Message  string
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s: %s", d.File, d.Line, d.Column, d.Severity, d.Message)
+}
+
+/**
+ * 检查配置文件，报告解析时被忽略的问题
+ * ini文件会检查重复的节点、无法识别的行、未闭合的括号及引号、无法解析的变量及未知的父节
+ * @param path string 文件路径
+ * @return []Diagnostic 按行号排序
+ */
+func Lint(path string) []Diagnostic {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return []Diagnostic{{File: path, Severity: SeverityError, Message: err.Error()}}
+	}
+
+	syntax := DetectSyntax(path, content)
+
+	config, loadErr := LoadFile(path, syntax)
+
+	var diags []Diagnostic
+	if syntax == "ini" {
+		diags = lintIni(path, content, config)
+	}
+
+	// 解析错误，同一行已经报告过的不再重复
+	if loadErr != nil {
+		diag := Diagnostic{File: path, Severity: SeverityError, Message: loadErr.Error()}
+
+		var parseErr *ParseError
+		if errors.As(loadErr, &parseErr) {
+			diag.File, diag.Line, diag.Column = parseErr.File, parseErr.Line, parseErr.Column
+			diag.Message = parseErr.Err.Error()
+		}
+
+		reported := false
+		for _, v := range diags {
+			if v.File == diag.File && v.Line == diag.Line && v.Severity == SeverityError {
+				reported = true
+				break
+			}
+		}
+
+		if !reported {
+			diags = append(diags, diag)
+		}
+	}
+
+	sort.SliceStable(diags, func(i, j int) bool {
+		if diags[i].Line != diags[j].Line {
+			return diags[i].Line < diags[j].Line
+		}
+
+		return diags[i].Column < diags[j].Column
+	})
+
+	return diags
+}
+
+// 检查ini文件，config 为解析失败时为nil，此时不检查变量
+func lintIni(path string, content []byte, config *Goini) []Diagnostic {
+	var diags []Diagnostic
+
+	report := func(line, column int, severity, format string, args ...interface{}) {
+		diags = append(diags, Diagnostic{
+			File:     path,
+			Line:     line,
+			Column:   column,
+			Severity: severity,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	// 已定义的节，及每个节中节点所在的行
+	sections := map[string]bool{defaultName: true}
+	keyLines := make(map[string]map[string]int)
+
+	// 本文件中定义的节，需要在子节之前定义
+	doc := parseDocument(content)
+	defined := make(map[string]bool)
+	for _, line := range doc.lines {
+		if line.kind == lineSection {
+			defined[line.section] = true
+		}
+	}
+
+	for idx, line := range doc.lines {
+		lineNum := idx + 1
+		indent := len(line.raw) - len(strings.TrimLeft(line.raw, " \t"))
+		trimStr := strings.TrimSpace(line.raw)
+
+		switch line.kind {
+		case lineSection:
+			sectionStr := rxFirstSection.FindString(trimStr)
+			sectionName := rxSection.ReplaceAllString(sectionStr, "")
+
+			if pos := strings.Index(sectionName, ":"); pos != -1 {
+				parent := sectionName[pos+1:]
+				if !sections[parent] && (defined[parent] || !config.hasSection(parent)) {
+					report(lineNum, indent+strings.LastIndex(sectionStr, parent)+1, SeverityError, "unknown parent section %q", parent)
+				}
+			}
+
+			sections[line.section] = true
+		case lineProperty:
+			if keyLines[line.section] == nil {
+				keyLines[line.section] = make(map[string]int)
+			}
+
+			if first, ok := keyLines[line.section][line.key]; ok {
+				report(lineNum, indent+1, SeverityWarning, "duplicate key %q, first defined on line %d", line.key, first)
+			} else {
+				keyLines[line.section][line.key] = lineNum
+			}
+
+			diags = append(diags, lintValue(path, lineNum, line, config)...)
+		case lineOther:
+			if rxInclude.MatchString(trimStr) {
+				continue
+			}
+
+			if strings.HasPrefix(trimStr, "[") {
+				report(lineNum, indent+1, SeverityError, "%v", ErrSectionNotClosed)
+				continue
+			}
+
+			report(lineNum, indent+1, SeverityError, "line is neither a section nor a key = value pair")
+		}
+	}
+
+	return diags
+}
+
+// 加载的配置中是否存在该节，包括 include 引入的文件中定义的节
+func (goini *Goini) hasSection(name string) bool {
+	if goini == nil {
+		return false
+	}
+
+	goini.mu.RLock()
+	defer goini.mu.RUnlock()
+
+	_, ok := goini.sections[name]
+
+	return ok
+}
+
+// 检查节点的值
+func lintValue(path string, lineNum int, line *docLine, config *Goini) []Diagnostic {
+	var diags []Diagnostic
+
+	report := func(column int, severity, format string, args ...interface{}) {
+		diags = append(diags, Diagnostic{
+			File:     path,
+			Line:     lineNum,
+			Column:   column,
+			Severity: severity,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	valueStr := line.raw[line.valStart:]
+
+	// 未闭合的引号
+	if valueStr != "" && (valueStr[0] == '"' || valueStr[0] == '\'') && quotedLen(valueStr) == -1 {
+		report(line.valStart+1, SeverityError, "quote %s is not closed", valueStr[:1])
+		return diags
+	}
+
+	// 未闭合的括号
+	if strings.HasPrefix(valueStr, "[") {
+		if _, err := findSliceString(valueStr); err != nil {
+			column := line.valStart + 1
+
+			var symErr *symbolError
+			if errors.As(err, &symErr) {
+				column += symErr.offset
+			}
+
+			report(column, SeverityError, "%v", err)
+			return diags
+		}
+	}
+
+	if config == nil {
+		return diags
+	}
+
+	// 无法解析的变量
+	valueStr = line.raw[line.valStart:line.valEnd]
+	for _, pos := range rxVariate.FindAllStringIndex(valueStr, -1) {
+		varStr := valueStr[pos[0]:pos[1]]
+		varName, _, hasModifier := splitVariable(varStr[2 : len(varStr)-1])
+
+		// 有默认值的变量不需要检查，必填的变量在解析时检查
+		if hasModifier {
+			continue
+		}
+
+		// 环境变量
+		if config.isEnvVariate(varName) {
+			if _, ok := config.lookupEnv(varName[len(envNamespace)+1:]); !ok {
+				report(line.valStart+pos[0]+1, SeverityWarning, "environment variable %s is not set", varName[len(envNamespace)+1:])
+			}
+
+			continue
+		}
+
+		section := defaultName
+		if colonPos := strings.Index(varName, ":"); colonPos > 0 {
+			section, varName = varName[:colonPos], varName[colonPos+1:]
+		}
+
+		if config.getValBySection(varName, section) == nil {
+			report(line.valStart+pos[0]+1, SeverityWarning, "reference %s resolves to nothing", varStr)
+			continue
+		}
+
+		// 整个值为变量时在解析时取值，引用之后才定义的节点取不到值
+		if pos[0] == 0 && pos[1] == len(valueStr) && config.getValBySection(line.key, line.section) == nil {
+			report(line.valStart+pos[0]+1, SeverityWarning, "reference %s is defined after it is used", varStr)
+		}
+	}
+
+	return diags
+}
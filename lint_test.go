@@ -0,0 +1,88 @@
+package goini
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.ini")
+	ioutil.WriteFile(path, []byte("env = prod\n"+
+		"env = dev\n"+
+		"just text\n"+
+		"name = \"goini\n"+
+		"dsn = ${db:host}:${db:port}\n"+
+		"[db]\n"+
+		"host = 127.0.0.1\n"+
+		"hosts = [a, [b, c]\n"+
+		"[replica:master]\n"+
+		"host = ${env}\n"), 0644)
+
+	testCases := []Diagnostic{
+		{Line: 2, Column: 1, Severity: SeverityWarning},
+		{Line: 3, Column: 1, Severity: SeverityError},
+		{Line: 4, Column: 8, Severity: SeverityError},
+		{Line: 8, Column: 9, Severity: SeverityError},
+		{Line: 9, Column: 10, Severity: SeverityError},
+	}
+
+	diags := Lint(path)
+	if len(diags) != len(testCases) {
+		t.Fatalf("Goini: Not as expected ret=%v, expect=%v", diags, len(testCases))
+	}
+
+	for i, v := range testCases {
+		if diags[i].Line != v.Line || diags[i].Column != v.Column || diags[i].Severity != v.Severity || diags[i].File != path {
+			t.Errorf("Goini: Not as expected ret=%v, expect=%v", diags[i], v)
+		}
+	}
+
+	ioutil.WriteFile(path, []byte("env = prod\n"+
+		"dsn = ${db:host}:${db:port}\n"+
+		"url = ${base}\n"+
+		"base = http://localhost\n"+
+		"port = ${db:port|3306}\n"+
+		"[db]\n"+
+		"host = 127.0.0.1\n"+
+		"[replica:db]\n"+
+		"host = ${env}\n"), 0644)
+
+	testCases = []Diagnostic{
+		{Line: 2, Column: 18, Severity: SeverityWarning},
+		{Line: 3, Column: 7, Severity: SeverityWarning},
+	}
+
+	diags = Lint(path)
+	if len(diags) != len(testCases) {
+		t.Fatalf("Goini: Not as expected ret=%v, expect=%v", diags, len(testCases))
+	}
+
+	for i, v := range testCases {
+		if diags[i].Line != v.Line || diags[i].Column != v.Column || diags[i].Severity != v.Severity {
+			t.Errorf("Goini: Not as expected ret=%v, expect=%v", diags[i], v)
+		}
+	}
+}
+
+func TestLint_IncludeParent(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "app.ini")
+	ioutil.WriteFile(filepath.Join(dir, "db.ini"), []byte("[db]\nhost = 127.0.0.1\n"), 0644)
+
+	// 父节定义在引入的文件中
+	ioutil.WriteFile(path, []byte("include = db.ini\n[replica:db]\nport = 3306\n"), 0644)
+
+	if diags := Lint(path); len(diags) != 0 {
+		t.Errorf("Goini: Not as expected ret=%v", diags)
+	}
+
+	// 本文件中的父节定义在子节之后
+	ioutil.WriteFile(path, []byte("include = db.ini\n[replica:cache]\nport = 3306\n[cache]\nsize = 10\n"), 0644)
+
+	diags := Lint(path)
+	if len(diags) != 1 || diags[0].Line != 2 || diags[0].Severity != SeverityError {
+		t.Errorf("Goini: Not as expected ret=%v", diags)
+	}
+}
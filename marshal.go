@@ -0,0 +1,458 @@
+package goini
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 有序的键值，保留结构体字段的顺序
+type marshalField struct {
+	name  string
+	value interface{}
+}
+
+// 结构体或map编码后的对象
+type marshalObject []marshalField
+
+/**
+ * 将结构体或map编码为指定格式，使用与 GetStruct 相同的 json 及 ini:"seq=..."、ini:"tpl=..." 标签
+ * ini格式中嵌套的结构体写为节，切片写为 [a, b] 形式，设置了 seq 的切片写为分隔的字符串
+ * @param v interface{} 结构体或map
+ * @param syntax string 文件格式，ini、yml 或 toml
+ * @return []byte, error
+ */
+func Marshal(v interface{}, syntax string) ([]byte, error) {
+	targetSyntax, ok := syntaxMap[syntax]
+	if !ok {
+		return nil, fmt.Errorf("goini: unsupported syntax %q", syntax)
+	}
+
+	val, err := marshalValue(reflect.ValueOf(v), "")
+	if err != nil {
+		return nil, err
+	}
+
+	obj, ok := val.(marshalObject)
+	if !ok {
+		return nil, errors.New("goini: marshal target must be a struct or map")
+	}
+
+	buf := &bytes.Buffer{}
+
+	switch targetSyntax {
+	case "yml":
+		err = writeYaml(buf, obj, "")
+	case "toml":
+		err = writeToml(buf, obj, "")
+	default:
+		err = writeIni(buf, obj)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// 编码值，结构体及map编码为 marshalObject，切片编码为 []interface{}
+func marshalValue(v reflect.Value, iniTag string) (interface{}, error) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, nil
+		}
+
+		v = v.Elem()
+	}
+
+	if !v.IsValid() {
+		return nil, nil
+	}
+
+	switch val := v.Interface().(type) {
+	case time.Time:
+		tpl := "2006-01-02 15:04:05"
+		tplName, tplVal := parseTag(iniTag, "=")
+		if tplName == "tpl" && tplVal != "" {
+			tpl = string(tplVal)
+		}
+
+		return val.Format(tpl), nil
+	case time.Duration:
+		return val.String(), nil
+	case json.RawMessage:
+		return string(val), nil
+	case encoding.TextMarshaler:
+		text, err := val.MarshalText()
+		if err != nil {
+			return nil, err
+		}
+
+		return string(text), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return marshalStruct(v)
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("goini: unsupported map key type %s", v.Type().Key())
+		}
+
+		keys := make([]string, 0, v.Len())
+		for _, key := range v.MapKeys() {
+			keys = append(keys, key.String())
+		}
+
+		sort.Strings(keys)
+
+		obj := make(marshalObject, 0, len(keys))
+		for _, key := range keys {
+			item, err := marshalValue(v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key())), "")
+			if err != nil {
+				return nil, err
+			}
+
+			if item != nil {
+				obj = append(obj, marshalField{name: key, value: item})
+			}
+		}
+
+		return obj, nil
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil, nil
+		}
+
+		arr := make([]interface{}, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			item, err := marshalValue(v.Index(i), iniTag)
+			if err != nil {
+				return nil, err
+			}
+
+			if item == nil {
+				item = ""
+			}
+
+			arr = append(arr, item)
+		}
+
+		// 设置了分隔符的切片写为字符串
+		seqName, seqVal := parseTag(iniTag, "=")
+		if seqName == "seq" && seqVal != "" {
+			items := make([]string, 0, len(arr))
+			for _, item := range arr {
+				items = append(items, fmt.Sprintf("%v", item))
+			}
+
+			return strings.Join(items, string(seqVal)), nil
+		}
+
+		return arr, nil
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint(), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	case reflect.String:
+		return v.String(), nil
+	}
+
+	return nil, fmt.Errorf("goini: unsupported type %s", v.Type())
+}
+
+// 编码结构体，字段名规则与 mapToStruct 一致
+func marshalStruct(v reflect.Value) (marshalObject, error) {
+	t := v.Type()
+	obj := make(marshalObject, 0, t.NumField())
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := field.Name
+		tag, option := parseTag(field.Tag.Get("json"), ",")
+
+		if tag == "-" {
+			continue
+		}
+
+		if tag != "" {
+			name = tag
+		}
+
+		if option.Contains("omitempty", ",") && v.Field(i).IsZero() {
+			continue
+		}
+
+		item, err := marshalValue(v.Field(i), field.Tag.Get("ini"))
+		if err != nil {
+			return nil, err
+		}
+
+		if item != nil {
+			obj = append(obj, marshalField{name: name, value: item})
+		}
+	}
+
+	return obj, nil
+}
+
+// 编码为ini格式，第一层的对象写为节，其他节点写在默认节
+func writeIni(buf *bytes.Buffer, obj marshalObject) error {
+	var sections marshalObject
+
+	for _, field := range obj {
+		if child, ok := field.value.(marshalObject); ok {
+			sections = append(sections, marshalField{name: field.name, value: child})
+			continue
+		}
+
+		if err := writeIniField(buf, "", field); err != nil {
+			return err
+		}
+	}
+
+	for _, section := range sections {
+		if buf.Len() > 0 {
+			buf.WriteString("\n")
+		}
+
+		buf.WriteString("[" + section.name + "]\n")
+
+		for _, field := range section.value.(marshalObject) {
+			if err := writeIniField(buf, "", field); err != nil {
+				return fmt.Errorf("goini: [%s] %w", section.name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// 编码ini节点，嵌套的对象写为以.分隔的key
+func writeIniField(buf *bytes.Buffer, prefix string, field marshalField) error {
+	key := field.name
+	if prefix != "" {
+		key = prefix + "." + key
+	}
+
+	if child, ok := field.value.(marshalObject); ok {
+		for _, item := range child {
+			if err := writeIniField(buf, key, item); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if arr, ok := field.value.([]interface{}); ok {
+		for _, item := range arr {
+			if _, ok := item.(marshalObject); ok {
+				return fmt.Errorf("%s: slice of objects cannot be written to ini", key)
+			}
+		}
+	}
+
+	valStr, err := formatIniValue(field.value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+
+	buf.WriteString(key + " = " + valStr + "\n")
+
+	return nil
+}
+
+// 编码为yaml格式，嵌套的对象使用两个空格缩进
+func writeYaml(buf *bytes.Buffer, obj marshalObject, indent string) error {
+	for _, field := range obj {
+		switch v := field.value.(type) {
+		case marshalObject:
+			if len(v) == 0 {
+				buf.WriteString(indent + field.name + ": {}\n")
+				continue
+			}
+
+			buf.WriteString(indent + field.name + ":\n")
+			if err := writeYaml(buf, v, indent+"  "); err != nil {
+				return err
+			}
+		case []interface{}:
+			if !hasObject(v) {
+				buf.WriteString(indent + field.name + ": " + formatFlow(v, ": ", formatYamlScalar) + "\n")
+				continue
+			}
+
+			// 对象数组使用 flow 格式
+			buf.WriteString(indent + field.name + ":\n")
+			for _, item := range v {
+				buf.WriteString(indent + "  - " + formatFlow(item, ": ", formatYamlScalar) + "\n")
+			}
+		default:
+			buf.WriteString(indent + field.name + ": " + formatYamlScalar(v) + "\n")
+		}
+	}
+
+	return nil
+}
+
+// 编码为toml格式，嵌套的对象写为表格，对象数组写为数组表格
+func writeToml(buf *bytes.Buffer, obj marshalObject, prefix string) error {
+	var tables, arrayTables marshalObject
+
+	for _, field := range obj {
+		switch v := field.value.(type) {
+		case marshalObject:
+			tables = append(tables, field)
+			continue
+		case []interface{}:
+			if len(v) > 0 && allObject(v) {
+				arrayTables = append(arrayTables, field)
+				continue
+			}
+
+			buf.WriteString(field.name + " = " + formatFlow(v, " = ", formatTomlScalar) + "\n")
+		default:
+			buf.WriteString(field.name + " = " + formatTomlScalar(v) + "\n")
+		}
+	}
+
+	for _, table := range tables {
+		name := joinKey(prefix, table.name)
+
+		if buf.Len() > 0 {
+			buf.WriteString("\n")
+		}
+
+		buf.WriteString("[" + name + "]\n")
+		if err := writeToml(buf, table.value.(marshalObject), name); err != nil {
+			return err
+		}
+	}
+
+	for _, table := range arrayTables {
+		name := joinKey(prefix, table.name)
+
+		for _, item := range table.value.([]interface{}) {
+			if buf.Len() > 0 {
+				buf.WriteString("\n")
+			}
+
+			buf.WriteString("[[" + name + "]]\n")
+			if err := writeToml(buf, item.(marshalObject), name); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// 以 flow 格式编码数组或对象，如 [a, b]、{k: v}
+func formatFlow(val interface{}, sep string, formatScalar func(interface{}) string) string {
+	switch v := val.(type) {
+	case marshalObject:
+		items := make([]string, 0, len(v))
+		for _, field := range v {
+			items = append(items, field.name+sep+formatFlow(field.value, sep, formatScalar))
+		}
+
+		return "{" + strings.Join(items, ", ") + "}"
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, formatFlow(item, sep, formatScalar))
+		}
+
+		return "[" + strings.Join(items, ", ") + "]"
+	}
+
+	return formatScalar(val)
+}
+
+// 编码yaml的值，必要时加上引号
+func formatYamlScalar(val interface{}) string {
+	valStr, ok := val.(string)
+	if !ok {
+		return fmt.Sprintf("%v", val)
+	}
+
+	needQuote := valStr == "" || valStr != strings.TrimSpace(valStr) ||
+		strings.ContainsAny(valStr, ":#,[]{}\"'\r\n") ||
+		strings.ContainsAny(valStr[:1], "-?&*!|>%@`$")
+
+	if !needQuote {
+		return valStr
+	}
+
+	// 单引号中的内容原样保留，双引号中转义 \n、\\ 及 "
+	if strings.ContainsAny(valStr, "\"\\") && !strings.ContainsAny(valStr, "'\r\n") {
+		return "'" + valStr + "'"
+	}
+
+	return "\"" + escapeString(valStr) + "\""
+}
+
+// 编码toml的值，字符串加上引号
+func formatTomlScalar(val interface{}) string {
+	switch v := val.(type) {
+	case string:
+		// 字面量字符串中的内容原样保留，基本字符串中转义 \n、\\ 及 "
+		if strings.ContainsAny(v, "\"\\") && !strings.ContainsAny(v, "'\r\n") {
+			return "'" + v + "'"
+		}
+
+		return "\"" + escapeString(v) + "\""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+
+	return fmt.Sprintf("%v", val)
+}
+
+// 数组中是否含有对象
+func hasObject(arr []interface{}) bool {
+	for _, item := range arr {
+		if _, ok := item.(marshalObject); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// 数组中是否都是对象
+func allObject(arr []interface{}) bool {
+	for _, item := range arr {
+		if _, ok := item.(marshalObject); !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// 以.连接key
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+
+	return prefix + "." + key
+}
@@ -0,0 +1,152 @@
+package goini
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+type marshalDb struct {
+	Host  string   `json:"host"`
+	Port  int      `json:"port"`
+	Hosts []string `json:"hosts" ini:"seq=|"`
+	Tags  []string `json:"tags"`
+}
+
+type marshalApp struct {
+	Name    string    `json:"name"`
+	Debug   bool      `json:"debug"`
+	Rate    float64   `json:"rate"`
+	Created time.Time `json:"created" ini:"tpl=2006-01-02"`
+	Secret  string    `json:"-"`
+	Note    string    `json:"note,omitempty"`
+	Db      marshalDb `json:"db"`
+}
+
+func TestMarshal(t *testing.T) {
+	app := marshalApp{
+		Name:    "goini",
+		Debug:   true,
+		Rate:    0.5,
+		Created: time.Date(2020, 1, 2, 0, 0, 0, 0, time.Local),
+		Secret:  "secret",
+		Db: marshalDb{
+			Host:  "127.0.0.1",
+			Port:  3306,
+			Hosts: []string{"a", "b"},
+			Tags:  []string{"x", "y"},
+		},
+	}
+
+	expect := "name = goini\ndebug = true\nrate = 0.5\ncreated = 2020-01-02\n\n[db]\nhost = 127.0.0.1\nport = 3306\nhosts = a|b\ntags = [x, y]\n"
+
+	data, err := Marshal(app, "ini")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != expect {
+		t.Errorf("Goini: Not as expected ret=%q, expect=%q", data, expect)
+	}
+
+	testCases := []struct {
+		Syntax  string
+		Key     string
+		Section string
+	}{
+		{Syntax: "ini", Key: "host", Section: "db"},
+		{Syntax: "yml", Key: "db.host", Section: "default"},
+		{Syntax: "toml", Key: "db.host", Section: "default"},
+	}
+
+	for _, v := range testCases {
+		data, err := Marshal(&app, v.Syntax)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		config, err := LoadBytes(data, v.Syntax)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if ret := config.GetString("name"); ret != "goini" {
+			t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, "goini")
+		}
+
+		if ret := config.GetString("created"); ret != "2020-01-02" {
+			t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, "2020-01-02")
+		}
+
+		if ret := config.GetString(v.Key, v.Section); ret != "127.0.0.1" {
+			t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, "127.0.0.1")
+		}
+
+		var hosts []string
+		config.GetSlice(strings.Replace(v.Key, "host", "hosts", 1), "|", &hosts, v.Section)
+		if strings.Join(hosts, ",") != "a,b" {
+			t.Errorf("Goini: Not as expected ret=%v, expect=%v", hosts, "[a b]")
+		}
+	}
+
+	if _, err := Marshal([]string{"a"}, "ini"); err == nil {
+		t.Errorf("Goini: Not as expected err=%v", err)
+	}
+}
+
+func TestMarshal_Escape(t *testing.T) {
+	type note struct {
+		Multi   string `json:"multi"`
+		Path    string `json:"path"`
+		Quotes  string `json:"quotes"`
+		Comment string `json:"comment"`
+		Mixed   string `json:"mixed"`
+	}
+
+	src := note{
+		Multi:   "line1\nline2",
+		Path:    `C:\dir\new`,
+		Quotes:  `say "hi", it's`,
+		Comment: "a # b; c",
+		Mixed:   "\"q\" \\ end\r\n",
+	}
+
+	// ini的引号中不支持转义，无法写入换行
+	if _, err := Marshal(struct {
+		Note note `json:"note"`
+	}{src}, "ini"); err == nil {
+		t.Errorf("Goini: Not as expected err=%v", err)
+	}
+
+	for _, syntax := range []string{"ini", "yml", "toml"} {
+		expect := src
+		if syntax == "ini" {
+			expect.Multi, expect.Quotes, expect.Mixed = "line1", `say "hi"`, `"q" \ end`
+		}
+
+		data, err := Marshal(struct {
+			Note note `json:"note"`
+		}{expect}, syntax)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		config, err := LoadBytes(data, syntax)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var ret note
+		if syntax == "ini" {
+			section := config.GetSection("note")
+			ret = note{Multi: section["multi"].(string), Path: section["path"].(string), Quotes: section["quotes"].(string),
+				Comment: section["comment"].(string), Mixed: section["mixed"].(string)}
+		} else {
+			config.GetStruct("note", &ret)
+		}
+
+		if ret != expect {
+			t.Errorf("Goini: Not as expected syntax=%v ret=%+v, expect=%+v\n%s", syntax, ret, expect, data)
+		}
+	}
+}
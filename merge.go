@@ -0,0 +1,378 @@
+package goini
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// 目录加载选项
+type DirOptions struct {
+	// 要加载的扩展名，如 ini、yml，为空时加载所有支持的格式
+	Extensions []string
+
+	// 目录不存在时返回空配置，而不是错误
+	IgnoreMissing bool
+}
+
+/**
+ * 按顺序加载多个配置文件并合并，后加载的文件优先级更高
+ * 每个文件的格式根据扩展名或内容推断，可以混合使用ini、yml及toml
+ * @param paths ...string 文件路径
+ * @return *Goini, error
+ */
+func LoadLayers(paths ...string) (*Goini, error) {
+	return LoadLayersWith(paths)
+}
+
+/**
+ * 与 LoadLayers 相同，同时应用加载选项
+ * @param paths []string 文件路径
+ * @param opts ...Option 加载选项，全部合并后应用，重新加载时会再次生效
+ * @return *Goini, error
+ */
+func LoadLayersWith(paths []string, opts ...Option) (*Goini, error) {
+	if len(paths) == 0 {
+		return nil, errors.New("goini: no layer to load")
+	}
+
+	config := newGoini(paths[0], "")
+	config.configure(opts)
+
+	if err := config.mergeFiles(paths); err != nil {
+		return nil, err
+	}
+
+	if err := config.finish(); err != nil {
+		return nil, err
+	}
+
+	config.loader = func(opts []Option) (*Goini, error) {
+		return LoadLayersWith(paths, opts...)
+	}
+
+	return config, nil
+}
+
+/**
+ * 按文件名顺序加载目录下的所有配置片段并合并，如 conf.d 目录
+ * 每个片段的格式由扩展名决定，后加载的片段优先级更高
+ * @param dir string 目录
+ * @param dirOpts DirOptions 目录加载选项
+ * @param opts ...Option 加载选项，全部合并后应用，重新加载时会再次生效
+ * @return *Goini, error
+ */
+func LoadDir(dir string, dirOpts DirOptions, opts ...Option) (*Goini, error) {
+	config := newGoini(dir, "")
+	config.configure(opts)
+
+	config.loader = func(opts []Option) (*Goini, error) {
+		return LoadDir(dir, dirOpts, opts...)
+	}
+
+	// 监听目录，以便发现新增或删除的片段
+	config.files = append(config.files, dir)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil && !(dirOpts.IgnoreMissing && errors.Is(err, os.ErrNotExist)) {
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		if dirOpts.matchExt(name) {
+			files = append(files, filepath.Join(dir, name))
+		}
+	}
+
+	sort.Strings(files)
+
+	if err := config.mergeFiles(files); err != nil {
+		return nil, err
+	}
+
+	if err := config.finish(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// 是否是需要加载的文件
+func (opts DirOptions) matchExt(name string) bool {
+	ext := strings.TrimPrefix(filepath.Ext(name), ".")
+	if ext == "" {
+		return false
+	}
+
+	if len(opts.Extensions) == 0 {
+		return syntaxByExt(name) != ""
+	}
+
+	for _, v := range opts.Extensions {
+		if strings.EqualFold(strings.TrimPrefix(v, "."), ext) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// 依次加载文件并合并到当前配置，必填变量在全部合并后检查
+func (goini *Goini) mergeFiles(paths []string) error {
+	layers := make([]*Goini, 0, len(paths))
+	mixed := false
+
+	for _, path := range paths {
+		layer := newGoini(path, "")
+		if err := layer.parseFile(path, ""); err != nil {
+			return err
+		}
+
+		layers = append(layers, layer)
+		mixed = mixed || layer.Syntax == "ini"
+	}
+
+	for _, layer := range layers {
+		// 含有ini文件时，yml、toml第一层的表格作为节合并
+		if mixed && layer.Syntax != "ini" {
+			layer.promoteTables()
+		}
+
+		if goini.Syntax == "" {
+			goini.Syntax = layer.Syntax
+		}
+
+		goini.files = append(goini.files, layer.files...)
+		goini.required = append(goini.required, layer.required...)
+
+		// 继承父节的节只合并自身的节点，全部合并后再继承
+		layer.ownSections()
+		for _, v := range layer.inherits {
+			goini.setParent(v.child, v.parent)
+		}
+
+		goini.Merge(layer)
+	}
+
+	goini.inherit()
+
+	return nil
+}
+
+// yml、toml默认节中第一层的表格转为同名的节，与ini的节一致
+func (goini *Goini) promoteTables() {
+	property, _ := goini.sections[defaultName].(map[string]interface{})
+
+	for name, val := range property {
+		table, ok := val.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		section, _ := goini.sections[name].(map[string]interface{})
+		if section == nil {
+			section = make(map[string]interface{})
+			goini.sections[name] = section
+		}
+
+		for key, item := range table {
+			section[key] = mergeValue(section[key], item)
+		}
+
+		// 与ini一样同时保存以.分隔的节点
+		leaves := make(map[string]interface{})
+		flattenValue("", table, leaves)
+
+		for key, item := range leaves {
+			if _, ok := item.(string); ok && strings.Contains(key, ".") {
+				section[key] = item
+			}
+		}
+
+		prefix := name + "."
+
+		delete(property, name)
+		for key := range property {
+			if strings.HasPrefix(key, prefix) {
+				delete(property, key)
+			}
+		}
+
+		for key, origin := range goini.origins[defaultName] {
+			if strings.HasPrefix(key, prefix) {
+				goini.setOrigin(name, key[len(prefix):], origin)
+				goini.setOrigin(defaultName, key, "")
+			}
+		}
+	}
+}
+
+// 节的继承关系 [child:parent]
+type inheritance struct {
+	child  string
+	parent string
+}
+
+// 记录继承关系，同一个子节只保留最后的父节
+func (goini *Goini) setParent(child, parent string) {
+	for i, v := range goini.inherits {
+		if v.child == child {
+			goini.inherits[i].parent = parent
+			return
+		}
+	}
+
+	goini.inherits = append(goini.inherits, inheritance{child: child, parent: parent})
+}
+
+// 继承父节的节只保留自身的节点及来源
+func (goini *Goini) ownSections() {
+	for child, own := range goini.own {
+		goini.sections[child] = own
+
+		for key := range goini.origins[child] {
+			if _, ok := own[key]; !ok {
+				goini.setOrigin(child, key, "")
+			}
+		}
+	}
+}
+
+// 按顺序重新继承父节，子节自身的节点优先，之后的文件中父节新增或修改的节点同样会被继承
+func (goini *Goini) inherit() {
+	for _, v := range goini.inherits {
+		parent, ok := goini.sections[v.parent].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		own, _ := goini.sections[v.child].(map[string]interface{})
+		for key, origin := range goini.origins[v.parent] {
+			if _, ok := own[key]; !ok {
+				goini.setOrigin(v.child, key, origin)
+			}
+		}
+
+		property, _ := copyValue(parent).(map[string]interface{})
+		goini.sections[v.child] = mergeValue(property, own)
+	}
+}
+
+/**
+ * 合并其他配置，节及嵌套的map会深度合并，other中的值优先
+ * [child:parent] 的继承在各自的配置中已经完成，LoadLayers 及 LoadDir 会在全部合并后重新继承
+ * @param other *Goini 要合并的配置
+ */
+func (goini *Goini) Merge(other *Goini) {
+	if other == nil || other == goini {
+		return
+	}
+
+	// 先取副本，避免同时持有两个对象的锁
+	other.mu.RLock()
+	sections, _ := copyValue(other.sections).(map[string]interface{})
+
+	// 保存时写入的内容，other 中覆盖的值不写入
+	overlaid := other.base != nil
+	base, _ := copyValue(other.base).(map[string]interface{})
+	if !overlaid {
+		base, _ = copyValue(other.sections).(map[string]interface{})
+	}
+
+	origins := make(map[string]map[string]string)
+	for section, keys := range other.origins {
+		origins[section] = make(map[string]string)
+		for key, origin := range keys {
+			origins[section][key] = origin
+		}
+	}
+	other.mu.RUnlock()
+
+	goini.mu.Lock()
+	defer goini.mu.Unlock()
+
+	// 合并后的内容与原文件不再一致
+	goini.doc = nil
+
+	// other 含有覆盖的值时，保留合并前的内容用于保存
+	if goini.base == nil && overlaid {
+		goini.base, _ = copyValue(goini.sections).(map[string]interface{})
+	}
+
+	for section, val := range sections {
+		goini.sections[section] = mergeValue(goini.sections[section], val)
+	}
+
+	if goini.base != nil {
+		for section, val := range base {
+			goini.base[section] = mergeValue(goini.base[section], val)
+		}
+	}
+
+	for section, keys := range origins {
+		for key, origin := range keys {
+			goini.setOrigin(section, key, origin)
+		}
+	}
+}
+
+/**
+ * 获取节点的来源文件，未找到时返回空
+ * @param key string 节点名
+ * @param args 可变参数，第一个参数为节名
+ * @return string
+ */
+func (goini *Goini) Source(key string, args ...interface{}) string {
+	goini.mu.RLock()
+	defer goini.mu.RUnlock()
+
+	section := defaultName
+	if len(args) > 0 {
+		section = fmt.Sprintf("%v", args[0])
+	}
+
+	return goini.origins[section][key]
+}
+
+// 记录节点来源，来源为空时删除记录
+func (goini *Goini) setOrigin(section, key, origin string) {
+	if section == "" {
+		section = defaultName
+	}
+
+	if origin == "" {
+		delete(goini.origins[section], key)
+		return
+	}
+
+	if goini.origins[section] == nil {
+		goini.origins[section] = make(map[string]string)
+	}
+
+	goini.origins[section][key] = origin
+}
+
+// 深度合并，src中的值优先
+func mergeValue(dst, src interface{}) interface{} {
+	dstMap, dstOk := dst.(map[string]interface{})
+	srcMap, srcOk := src.(map[string]interface{})
+
+	if !dstOk || !srcOk {
+		return src
+	}
+
+	for k, v := range srcMap {
+		dstMap[k] = mergeValue(dstMap[k], v)
+	}
+
+	return dstMap
+}
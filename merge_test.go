@@ -0,0 +1,311 @@
+package goini
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadLayers(t *testing.T) {
+	dir := t.TempDir()
+
+	base := filepath.Join(dir, "base.ini")
+	ioutil.WriteFile(base, []byte("env = base\n[database]\ndb.host = 127.0.0.1\ndb.port = 3306\n"), 0644)
+
+	prod := filepath.Join(dir, "prod.ini")
+	ioutil.WriteFile(prod, []byte("env = prod\n[database]\ndb.port = 3307\n[redis:database]\ndb.port = 6379\n"), 0644)
+
+	local := filepath.Join(dir, "local.yml")
+	ioutil.WriteFile(local, []byte("env: local\ncache:\n  size: 10\n"), 0644)
+
+	config, err := LoadLayers(base, prod, local)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		Key     string
+		Section string
+		Expect  string
+		Source  string
+	}{
+		{Key: "env", Section: "default", Expect: "local", Source: local},
+		{Key: "size", Section: "cache", Expect: "10", Source: local},
+		{Key: "db.host", Section: "database", Expect: "127.0.0.1", Source: base},
+		{Key: "db.port", Section: "database", Expect: "3307", Source: prod},
+		{Key: "db.port", Section: "redis", Expect: "6379", Source: prod},
+	}
+
+	for _, v := range testCases {
+		if ret := config.GetString(v.Key, v.Section); ret != v.Expect {
+			t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, v.Expect)
+		}
+
+		if ret := config.Source(v.Key, v.Section); ret != v.Source {
+			t.Errorf("Goini: Not as expected source=%v, expect=%v", ret, v.Source)
+		}
+	}
+
+	var db map[string]string
+	config.GetMap("db", &db, "database")
+	if db["host"] != "127.0.0.1" || db["port"] != "3307" {
+		t.Errorf("Goini: Not as expected ret=%v", db)
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+
+	ioutil.WriteFile(filepath.Join(dir, "10-base.ini"), []byte("env = base\n[database]\ndb.port = 3306\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "20-cache.yml"), []byte("cache:\n  size: 10\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "30-env.toml"), []byte("env = \"prod\"\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "README.md"), []byte("env = readme\n"), 0644)
+
+	config, err := LoadDir(dir, DirOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ret := config.GetString("env"); ret != "prod" {
+		t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, "prod")
+	}
+
+	if ret := config.GetInt("size", "cache"); ret != 10 {
+		t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, 10)
+	}
+
+	if ret := config.GetInt("db.port", "database"); ret != 3306 {
+		t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, 3306)
+	}
+
+	config, err = LoadDir(dir, DirOptions{Extensions: []string{"ini"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ret := config.GetString("env"); ret != "base" {
+		t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, "base")
+	}
+
+	broken := filepath.Join(dir, "40-broken.ini")
+	ioutil.WriteFile(broken, []byte("[broken\n"), 0644)
+
+	_, err = LoadDir(dir, DirOptions{})
+
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.File != broken {
+		t.Errorf("Goini: Not as expected err=%v", err)
+	}
+
+	if _, err := LoadDir(filepath.Join(dir, "missing"), DirOptions{IgnoreMissing: true}); err != nil {
+		t.Errorf("Goini: Not as expected err=%v", err)
+	}
+}
+
+func TestRequiredAfterMerge(t *testing.T) {
+	dir := t.TempDir()
+
+	base := filepath.Join(dir, "base.ini")
+	ioutil.WriteFile(base, []byte("dsn = mysql://root:${db:pass|?db password is required}@localhost\n"), 0644)
+
+	local := filepath.Join(dir, "local.ini")
+	ioutil.WriteFile(local, []byte("[db]\npass = x\n"), 0644)
+
+	// 后加载的文件提供必填变量
+	config, err := LoadLayers(base, local)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ret := config.GetString("dsn"); ret != "mysql://root:x@localhost" {
+		t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, "mysql://root:x@localhost")
+	}
+
+	if err := config.Reload(); err != nil {
+		t.Errorf("Goini: Not as expected err=%v", err)
+	}
+
+	// 所有文件都未提供时加载失败，错误指向引用的位置
+	_, err = LoadLayers(base)
+
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.File != base || parseErr.Line != 1 || !errors.Is(err, ErrMissingVariable) {
+		t.Errorf("Goini: Not as expected err=%v", err)
+	}
+
+	// 覆盖的值提供必填变量
+	content := "dsn = mysql://root:${db.pass|?db password is required}@localhost\n"
+
+	config, err = LoadString(content, "ini", WithOverrides(map[string]string{"db.pass": "x"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ret := config.GetString("dsn"); ret != "mysql://root:x@localhost" {
+		t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, "mysql://root:x@localhost")
+	}
+
+	if _, err := LoadString(content, "ini"); !errors.Is(err, ErrMissingVariable) {
+		t.Errorf("Goini: Not as expected err=%v", err)
+	}
+}
+
+func TestLoadLayers_Options(t *testing.T) {
+	t.Setenv("APP_DATABASE_DB_HOST", "10.0.0.1")
+
+	dir := t.TempDir()
+
+	base := filepath.Join(dir, "base.ini")
+	ioutil.WriteFile(base, []byte("[database]\ndb.host = 127.0.0.1\ndb.port = 3306\n"), 0644)
+
+	local := filepath.Join(dir, "local.yml")
+	ioutil.WriteFile(local, []byte("env: local\n"), 0644)
+
+	conf := filepath.Join(dir, "conf.d")
+	os.Mkdir(conf, 0755)
+	ioutil.WriteFile(filepath.Join(conf, "10-base.ini"), []byte("[database]\ndb.host = 127.0.0.1\n"), 0644)
+
+	overrides := WithOverrides(map[string]string{"env": "prod", "database.db.port": "3307"})
+
+	layers, err := LoadLayersWith([]string{base, local}, WithEnv("APP"), overrides)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dirConfig, err := LoadDir(conf, DirOptions{}, WithEnv("APP"), overrides)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 重新加载后仍然生效
+	ioutil.WriteFile(local, []byte("env: test\n"), 0644)
+	ioutil.WriteFile(filepath.Join(conf, "20-local.ini"), []byte("env = test\n"), 0644)
+
+	for _, config := range []*Goini{layers, dirConfig} {
+		for i := 0; i < 2; i++ {
+			testCases := []struct {
+				Key     string
+				Section string
+				Expect  string
+			}{
+				{Key: "env", Section: "default", Expect: "prod"},
+				{Key: "db.host", Section: "database", Expect: "10.0.0.1"},
+			}
+
+			for _, v := range testCases {
+				if ret := config.GetString(v.Key, v.Section); ret != v.Expect {
+					t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, v.Expect)
+				}
+			}
+
+			if err := config.Reload(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	if ret := layers.GetInt("db.port", "database"); ret != 3307 {
+		t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, 3307)
+	}
+}
+
+func TestLoadLayers_Inherit(t *testing.T) {
+	dir := t.TempDir()
+
+	base := filepath.Join(dir, "base.ini")
+	ioutil.WriteFile(base, []byte("[database]\nhost = 127.0.0.1\nport = 3306\ndb.name = app\n[redis:database]\nport = 6379\n"), 0644)
+
+	prod := filepath.Join(dir, "prod.ini")
+	ioutil.WriteFile(prod, []byte("[database]\nhost = 10.0.0.1\nuser = root\ndb.name = prod\n[cache:redis]\nsize = 10\n"), 0644)
+
+	config, err := LoadLayers(base, prod)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 之后的文件中父节修改的节点同样被继承，子节自身的节点优先
+	testCases := []struct {
+		Key     string
+		Section string
+		Expect  string
+		Source  string
+	}{
+		{Key: "host", Section: "redis", Expect: "10.0.0.1", Source: prod},
+		{Key: "user", Section: "redis", Expect: "root", Source: prod},
+		{Key: "port", Section: "redis", Expect: "6379", Source: base},
+		{Key: "db.name", Section: "redis", Expect: "prod", Source: prod},
+		{Key: "host", Section: "cache", Expect: "10.0.0.1", Source: prod},
+		{Key: "port", Section: "cache", Expect: "6379", Source: base},
+		{Key: "size", Section: "cache", Expect: "10", Source: prod},
+	}
+
+	for _, v := range testCases {
+		if ret := config.GetString(v.Key, v.Section); ret != v.Expect {
+			t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, v.Expect)
+		}
+
+		if ret := config.Source(v.Key, v.Section); ret != v.Source {
+			t.Errorf("Goini: Not as expected source=%v, expect=%v", ret, v.Source)
+		}
+	}
+
+	var db map[string]string
+	config.GetMap("db", &db, "redis")
+	if db["name"] != "prod" {
+		t.Errorf("Goini: Not as expected ret=%v", db)
+	}
+}
+
+func TestLoadLayers_MixedSyntax(t *testing.T) {
+	dir := t.TempDir()
+
+	base := filepath.Join(dir, "10-base.ini")
+	ioutil.WriteFile(base, []byte("env = base\n[database]\nhost = a\nport = 3306\npool.size = 5\n"), 0644)
+
+	local := filepath.Join(dir, "20-local.toml")
+	ioutil.WriteFile(local, []byte("env = \"local\"\n[database]\nhost = \"b\"\n[database.pool]\nsize = 10\n"), 0644)
+
+	layers, err := LoadLayers(base, local)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dirConfig, err := LoadDir(dir, DirOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// toml的表格与ini的节合并，后加载的文件优先
+	for _, config := range []*Goini{layers, dirConfig} {
+		testCases := []struct {
+			Key     string
+			Section string
+			Expect  string
+			Source  string
+		}{
+			{Key: "env", Section: "default", Expect: "local", Source: local},
+			{Key: "host", Section: "database", Expect: "b", Source: local},
+			{Key: "port", Section: "database", Expect: "3306", Source: base},
+			{Key: "pool.size", Section: "database", Expect: "10", Source: local},
+			{Key: "database.host", Section: "default", Expect: ""},
+		}
+
+		for _, v := range testCases {
+			if ret := config.GetString(v.Key, v.Section); ret != v.Expect {
+				t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, v.Expect)
+			}
+
+			if ret := config.Source(v.Key, v.Section); ret != v.Source {
+				t.Errorf("Goini: Not as expected source=%v, expect=%v", ret, v.Source)
+			}
+		}
+
+		var pool map[string]int
+		config.GetMap("pool", &pool, "database")
+		if pool["size"] != 10 {
+			t.Errorf("Goini: Not as expected ret=%v", pool)
+		}
+	}
+}
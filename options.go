@@ -0,0 +1,222 @@
+package goini
+
+import (
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Option 加载选项，在解析前记录，环境变量及覆盖的节点在解析完成后生效，重新加载时会再次生效
+type Option func(*Goini)
+
+// 环境变量名中不允许的字符
+var rxEnvName = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+/**
+ * 使用环境变量覆盖已有的节点，如 APP_DATABASE_DB_HOST 覆盖 [database] 节的 db.host
+ * 默认节的节点不含节名，如 APP_ENV 覆盖 env
+ * @param prefix string 环境变量前缀，可以为空
+ * @return Option
+ */
+func WithEnv(prefix string) Option {
+	return WithEnvKeyFunc(func(section, key string) string {
+		return EnvName(prefix, section, key)
+	})
+}
+
+/**
+ * 使用环境变量覆盖已有的节点，环境变量名由 fn 生成
+ * @param fn func(section, key string) string 根据节名及节点名生成环境变量名，返回空时不覆盖
+ * @return Option
+ */
+func WithEnvKeyFunc(fn func(section, key string) string) Option {
+	return func(goini *Goini) {
+		goini.envKeys = append(goini.envKeys, fn)
+	}
+}
+
+/**
+ * 生成默认的环境变量名，以 _ 连接前缀、节名及节点名并转为大写，其他字符替换为 _
+ * @param prefix string 前缀
+ * @param section string 节名，默认节不含节名
+ * @param key string 节点名
+ * @return string
+ */
+func EnvName(prefix, section, key string) string {
+	var parts []string
+	if prefix != "" {
+		parts = append(parts, prefix)
+	}
+
+	if section != "" && section != defaultName {
+		parts = append(parts, section)
+	}
+
+	parts = append(parts, key)
+
+	return strings.ToUpper(rxEnvName.ReplaceAllString(strings.Join(parts, "_"), "_"))
+}
+
+/**
+ * 应用选项，重新加载时会再次生效
+ * @param opts ...Option
+ */
+func (goini *Goini) Apply(opts ...Option) {
+	goini.mu.Lock()
+	defer goini.mu.Unlock()
+
+	goini.configure(opts)
+	goini.applyOverlays()
+}
+
+// 记录选项，在解析前调用，WithEnvExpansion 等选项对解析过程同样生效，调用方需持有锁
+func (goini *Goini) configure(opts []Option) {
+	for _, opt := range opts {
+		if opt != nil {
+			goini.options = append(goini.options, opt)
+			opt(goini)
+		}
+	}
+}
+
+// 应用环境变量及覆盖的节点，覆盖的节点最后应用，优先级最高，调用方需持有锁
+func (goini *Goini) applyOverlays() {
+	for _, fn := range goini.envKeys {
+		goini.applyEnv(fn)
+	}
+
+	goini.applyOverrides()
+}
+
+// 解析完成后应用环境变量及覆盖的节点，再检查必填变量，合并的文件及覆盖的值都可以提供必填变量，调用方需持有锁
+func (goini *Goini) finish() error {
+	goini.applyOverlays()
+
+	err := goini.checkRequired()
+	goini.required = nil
+
+	return err
+}
+
+/**
+ * 覆盖指定的节点，优先级高于文件及环境变量，节点不存在时新增
+ * key 为 section.key 形式，第一段为已存在的节名时设置该节的节点，否则设置默认节的节点
+ * @param overrides map[string]string 节点及对应的值
+ * @return Option
+ */
+func WithOverrides(overrides map[string]string) Option {
+	return func(goini *Goini) {
+		if goini.overrides == nil {
+			goini.overrides = make(map[string]string, len(overrides))
+		}
+
+		for key, val := range overrides {
+			goini.overrides[key] = val
+		}
+	}
+}
+
+// 应用覆盖的节点，只修改读取的值，保存时仍写入文件中的值
+func (goini *Goini) applyOverrides() {
+	keys := make([]string, 0, len(goini.overrides))
+	for key := range goini.overrides {
+		keys = append(keys, key)
+	}
+
+	// 按顺序应用，db 与 db.host 同时存在时结果保持一致
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		section, name := goini.overrideKey(key)
+		if name == "" {
+			continue
+		}
+
+		goini.overlay(section, name, goini.overrides[key], "override")
+	}
+}
+
+// 拆分覆盖的节点名，第一段为已存在的节名时作为节名
+func (goini *Goini) overrideKey(key string) (string, string) {
+	if pos := strings.Index(key, "."); pos > 0 {
+		if _, ok := goini.sections[key[:pos]]; ok {
+			return key[:pos], key[pos+1:]
+		}
+	}
+
+	return defaultName, key
+}
+
+// 使用环境变量覆盖已有的节点，只修改读取的值，保存时仍写入文件中的值
+func (goini *Goini) applyEnv(fn func(section, key string) string) {
+	for section, val := range goini.sections {
+		leaves := make(map[string]interface{})
+		flattenValue("", val, leaves)
+
+		for key := range leaves {
+			name := fn(section, key)
+			if name == "" {
+				continue
+			}
+
+			if envVal, ok := os.LookupEnv(name); ok {
+				goini.overlay(section, key, envVal, "env:"+name)
+			}
+		}
+	}
+}
+
+/**
+ * 开启 ${env:NAME} 环境变量引用，默认不读取任何环境变量
+ * 可以只允许读取指定的环境变量，或使用 AnyEnv 允许读取所有环境变量
+ * @param allow func(name string) bool 是否允许读取该环境变量，为nil时不读取任何环境变量
+ * @return Option
+ */
+func WithEnvExpansion(allow func(name string) bool) Option {
+	return func(goini *Goini) {
+		goini.envAllow = allow
+	}
+}
+
+// 允许读取所有环境变量，与 WithEnvExpansion 一起使用
+func AnyEnv(name string) bool {
+	return true
+}
+
+/**
+ * 只允许读取指定的环境变量，与 WithEnvExpansion 一起使用
+ * @param names ...string 环境变量名
+ * @return func(name string) bool
+ */
+func AllowEnv(names ...string) func(name string) bool {
+	allowed := make(map[string]bool, len(names))
+	for _, name := range names {
+		allowed[name] = true
+	}
+
+	return func(name string) bool {
+		return allowed[name]
+	}
+}
+
+// 覆盖节点的值并记录来源，第一次覆盖前保留原有的节内容用于保存，调用方需持有锁
+func (goini *Goini) overlay(section, key string, val interface{}, origin string) {
+	if goini.base == nil {
+		goini.base, _ = copyValue(goini.sections).(map[string]interface{})
+	}
+
+	sectionName, property := goini.sectionName, goini.property
+
+	goini.sectionName = section
+	goini.property, _ = goini.sections[section].(map[string]interface{})
+	if goini.property == nil {
+		goini.property = make(map[string]interface{})
+		goini.sections[section] = goini.property
+	}
+
+	goini.setProperty(key, val)
+	goini.setOrigin(section, key, origin)
+
+	goini.sectionName, goini.property = sectionName, property
+}
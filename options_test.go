@@ -0,0 +1,325 @@
+package goini
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWithEnv(t *testing.T) {
+	t.Setenv("APP_ENV", "prod")
+	t.Setenv("APP_DATABASE_DB_HOST", "10.0.0.1")
+	t.Setenv("APP_DATABASE_DB_MISSING", "ignored")
+
+	path := filepath.Join(t.TempDir(), "app.ini")
+	ioutil.WriteFile(path, []byte("env = dev\n[database]\ndb.host = 127.0.0.1\ndb.port = 3306\n"), 0644)
+
+	config, err := LoadFile(path, "", WithEnv("APP"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		Key     string
+		Section string
+		Expect  string
+	}{
+		{Key: "env", Section: "default", Expect: "prod"},
+		{Key: "db.host", Section: "database", Expect: "10.0.0.1"},
+		{Key: "db.port", Section: "database", Expect: "3306"},
+		{Key: "db.missing", Section: "database", Expect: ""},
+	}
+
+	for _, v := range testCases {
+		if ret := config.GetString(v.Key, v.Section); ret != v.Expect {
+			t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, v.Expect)
+		}
+	}
+
+	db := struct {
+		Host string `json:"host"`
+	}{}
+
+	config.GetStruct("db", &db, "database")
+	if db.Host != "10.0.0.1" {
+		t.Errorf("Goini: Not as expected ret=%v, expect=%v", db.Host, "10.0.0.1")
+	}
+
+	section := config.GetSection("database")
+	if ret := section["db"].(map[string]interface{})["host"]; ret != "10.0.0.1" {
+		t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, "10.0.0.1")
+	}
+
+	if ret := config.Source("db.host", "database"); ret != "env:APP_DATABASE_DB_HOST" {
+		t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, "env:APP_DATABASE_DB_HOST")
+	}
+
+	// 重新加载后仍然生效，保存时不写入环境变量的值
+	ioutil.WriteFile(path, []byte("env = test\n[database]\ndb.host = 127.0.0.2\n"), 0644)
+	if err := config.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	if ret := config.GetString("db.host", "database"); ret != "10.0.0.1" {
+		t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, "10.0.0.1")
+	}
+
+	config.Set("name", "goini")
+	config.SaveFile(path)
+
+	data, _ := ioutil.ReadFile(path)
+	if strings.Contains(string(data), "10.0.0.1") || strings.Contains(string(data), "prod") {
+		t.Errorf("Goini: Not as expected ret=%q", data)
+	}
+}
+
+func TestWithEnvKeyFunc(t *testing.T) {
+	t.Setenv("DB_HOST", "10.0.0.1")
+
+	config, err := LoadString("db:\n  host: 127.0.0.1\n", "yml", WithEnvKeyFunc(func(section, key string) string {
+		return strings.ToUpper(strings.Replace(key, ".", "_", -1))
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ret := config.GetString("db.host"); ret != "10.0.0.1" {
+		t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, "10.0.0.1")
+	}
+
+	var db map[string]string
+	config.GetMap("db", &db)
+	if db["host"] != "10.0.0.1" {
+		t.Errorf("Goini: Not as expected ret=%v, expect=%v", db["host"], "10.0.0.1")
+	}
+}
+
+func TestWithOverrides(t *testing.T) {
+	t.Setenv("APP_DATABASE_DB_HOST", "10.0.0.1")
+
+	path := filepath.Join(t.TempDir(), "app.ini")
+	ioutil.WriteFile(path, []byte("env = dev\n[database]\ndb.host = 127.0.0.1\ndb.port = 3306\n"), 0644)
+
+	overrides := map[string]string{
+		"env":              "prod",
+		"app.name":         "goini",
+		"database.db.host": "10.0.0.2",
+		"database.db.user": "root",
+	}
+
+	config, err := LoadFile(path, "", WithOverrides(overrides), WithEnv("APP"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		Key     string
+		Section string
+		Expect  string
+	}{
+		{Key: "env", Section: "default", Expect: "prod"},
+		{Key: "app.name", Section: "default", Expect: "goini"},
+		{Key: "db.host", Section: "database", Expect: "10.0.0.2"},
+		{Key: "db.port", Section: "database", Expect: "3306"},
+		{Key: "db.user", Section: "database", Expect: "root"},
+	}
+
+	for _, v := range testCases {
+		if ret := config.GetString(v.Key, v.Section); ret != v.Expect {
+			t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, v.Expect)
+		}
+	}
+
+	db := struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+		User string `json:"user"`
+	}{}
+
+	config.GetStruct("db", &db, "database")
+	if db.Host != "10.0.0.2" || db.Port != 3306 || db.User != "root" {
+		t.Errorf("Goini: Not as expected ret=%+v", db)
+	}
+
+	if ret := config.Source("db.host", "database"); ret != "override" {
+		t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, "override")
+	}
+
+	// 重新加载后仍然生效
+	ioutil.WriteFile(path, []byte("env = test\n[database]\ndb.host = 127.0.0.3\n"), 0644)
+	if err := config.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	if ret := config.GetString("db.host", "database"); ret != "10.0.0.2" {
+		t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, "10.0.0.2")
+	}
+
+	// 命令行参数
+	flags := flag.NewFlagSet("app", flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+
+	set := RegisterFlags(flags)
+
+	if err := flags.Parse([]string{"-set", "database.db.host=10.0.0.4", "-set", "env = prod"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if ret := set.Overrides(); ret["database.db.host"] != "10.0.0.4" || ret["env"] != "prod" {
+		t.Errorf("Goini: Not as expected ret=%v", ret)
+	}
+
+	if err := flags.Parse([]string{"-set", "env"}); err == nil {
+		t.Errorf("Goini: Not as expected err=%v", err)
+	}
+}
+
+func TestOverlay_Save(t *testing.T) {
+	t.Setenv("APP_DB_PASSWORD", "secret-env")
+
+	path := filepath.Join(t.TempDir(), "app.yml")
+	ioutil.WriteFile(path, []byte("name: app\ndb:\n  password: dev\n  user: root\n"), 0644)
+
+	overrides := map[string]string{
+		"db.user":  "secret-set",
+		"db.token": "secret-token",
+	}
+
+	config, err := LoadFile(path, "", WithEnv("APP"), WithOverrides(overrides))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ret := config.GetString("db.password"); ret != "secret-env" {
+		t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, "secret-env")
+	}
+
+	// 合并后保存，仍写入文件中的值
+	cache, err := LoadString("[cache]\nhost = 127.0.0.1\n", "ini")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config.Merge(cache)
+	config.Set("name", "goini")
+	config.Set("db.user", "admin")
+
+	if ret := config.GetString("db.user"); ret != "admin" {
+		t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, "admin")
+	}
+
+	buf := &bytes.Buffer{}
+	if _, err := config.WriteTo(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	saved, err := LoadString(buf.String(), "ini")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		Key     string
+		Section string
+		Expect  string
+	}{
+		{Key: "name", Section: "default", Expect: "goini"},
+		{Key: "db.password", Section: "default", Expect: "dev"},
+		{Key: "db.user", Section: "default", Expect: "admin"},
+		{Key: "db.token", Section: "default", Expect: ""},
+		{Key: "host", Section: "cache", Expect: "127.0.0.1"},
+	}
+
+	for _, v := range testCases {
+		if ret := saved.GetString(v.Key, v.Section); ret != v.Expect {
+			t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, v.Expect)
+		}
+	}
+
+	if strings.Contains(buf.String(), "secret") {
+		t.Errorf("Goini: Not as expected ret=%q", buf.String())
+	}
+}
+
+func TestWithEnvExpansion(t *testing.T) {
+	t.Setenv("GOINI_HOME", "/home/goini")
+	t.Setenv("GOINI_SECRET", "secret")
+
+	sources := []struct {
+		Syntax string
+		Data   string
+	}{
+		{Syntax: "ini", Data: "data_dir = ${env:GOINI_HOME}/data\nhome = ${env:GOINI_HOME}\nsecret = ${env:GOINI_SECRET}\n"},
+		{Syntax: "yml", Data: "data_dir: ${env:GOINI_HOME}/data\nhome: ${env:GOINI_HOME}\nsecret: ${env:GOINI_SECRET}\n"},
+		{Syntax: "toml", Data: "data_dir = \"${env:GOINI_HOME}/data\"\nhome = \"${env:GOINI_HOME}\"\nsecret = \"${env:GOINI_SECRET}\"\n"},
+	}
+
+	for _, source := range sources {
+		testCases := []struct {
+			Options []Option
+			Key     string
+			Expect  string
+		}{
+			{Key: "home", Expect: "${env:GOINI_HOME}"},
+			{Options: []Option{WithEnvExpansion(AnyEnv)}, Key: "data_dir", Expect: "/home/goini/data"},
+			{Options: []Option{WithEnvExpansion(AnyEnv)}, Key: "home", Expect: "/home/goini"},
+			{Options: []Option{WithEnvExpansion(AnyEnv)}, Key: "secret", Expect: "secret"},
+			{Options: []Option{WithEnvExpansion(AllowEnv("GOINI_HOME"))}, Key: "home", Expect: "/home/goini"},
+			{Options: []Option{WithEnvExpansion(AllowEnv("GOINI_HOME"))}, Key: "secret", Expect: "${env:GOINI_SECRET}"},
+			{Options: []Option{WithEnvExpansion(nil)}, Key: "home", Expect: "${env:GOINI_HOME}"},
+		}
+
+		for _, v := range testCases {
+			config, err := LoadString(source.Data, source.Syntax, v.Options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if ret := config.GetString(v.Key); ret != v.Expect {
+				t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, v.Expect)
+			}
+		}
+	}
+
+	// 存在 [env] 节时以节中的值优先
+	config, err := LoadString("home = ${env:GOINI_HOME}\ndsn = ${env:dsn}\n[env]\ndsn = mysql\n", "ini", WithEnvExpansion(AnyEnv))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ret := config.GetString("dsn"); ret != "mysql" {
+		t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, "mysql")
+	}
+
+	if ret := config.GetString("home"); ret != "/home/goini" {
+		t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, "/home/goini")
+	}
+
+	// 不允许读取的环境变量既不展开，也不能满足必填变量
+	allow := WithEnvExpansion(AllowEnv("GOINI_HOME"))
+
+	config, err = LoadString("secret = ${env:GOINI_SECRET|none}\nhome = ${env:GOINI_HOME|?}\n", "ini", allow)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ret := config.GetString("secret"); ret != "none" {
+		t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, "none")
+	}
+
+	_, err = LoadString("secret = ${env:GOINI_SECRET|?GOINI_SECRET must be set}\n", "ini", allow)
+	if !errors.Is(err, ErrMissingVariable) {
+		t.Errorf("Goini: Not as expected err=%v", err)
+	}
+
+	dir := t.TempDir()
+	ioutil.WriteFile(filepath.Join(dir, "app.yml"), []byte("secret: ${env:GOINI_SECRET|?}\n"), 0644)
+
+	if _, err := LoadFile(filepath.Join(dir, "app.yml"), "", WithEnvExpansion(nil)); !errors.Is(err, ErrMissingVariable) {
+		t.Errorf("Goini: Not as expected err=%v", err)
+	}
+}
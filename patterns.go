@@ -13,6 +13,12 @@ const (
 	QuotationEnd   string = `"|'`             // 匹配单或双引号
 	Variate        string = `(?U)\$\{.*\}`    // 匹配变量
 	YamlFlow       string = `^\{.*\}`
+	Include        string = `^include\s*=`                                                                             // 引入其他文件
+	YamlKey        string = `^[\w.\-"']+\s*:(\s|$)`                                                                    // yaml key:
+	TomlArrayTable string = `^\[\[.*\]\]`                                                                              // toml 数组表格
+	TomlNumber     string = `^[+-]?(\d[\d_]*(\.\d[\d_]*)?([eE][+-]?\d+)?|0x[0-9a-fA-F_]+|0o[0-7_]+|0b[01_]+|inf|nan)$` // toml 数值
+	TomlDate       string = `^\d{4}-\d{2}-\d{2}([T ]\d{2}:\d{2}:\d{2}.*)?$`                                            // toml 日期
+	SizeValue      string = `^(\d+(?:\.\d+)?)\s*([A-Za-z]*)$`                                                          // 字节大小 10MB
 )
 
 var (
@@ -24,4 +30,10 @@ var (
 	rxQuotationEnd   = regexp.MustCompile(QuotationEnd)
 	rxVariate        = regexp.MustCompile(Variate)
 	rxYamlFlow       = regexp.MustCompile(YamlFlow)
+	rxInclude        = regexp.MustCompile(Include)
+	rxYamlKey        = regexp.MustCompile(YamlKey)
+	rxTomlArrayTable = regexp.MustCompile(TomlArrayTable)
+	rxTomlNumber     = regexp.MustCompile(TomlNumber)
+	rxTomlDate       = regexp.MustCompile(TomlDate)
+	rxByteSize       = regexp.MustCompile(SizeValue)
 )
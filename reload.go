@@ -0,0 +1,94 @@
+package goini
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+)
+
+/**
+ * 注册校验方法，重新加载时新配置需要通过所有校验才会生效
+ * @param fn func(*Goini) error 校验方法，参数为新加载的配置
+ */
+func (goini *Goini) AddValidator(fn func(*Goini) error) {
+	goini.mu.Lock()
+	defer goini.mu.Unlock()
+
+	goini.validators = append(goini.validators, fn)
+}
+
+/**
+ * 重新加载配置：重新解析、应用加载选项、检查必填变量、校验，全部通过后替换当前配置并调用 OnChange 回调
+ * 解析或校验失败时保留原有配置并返回错误
+ * @return error
+ */
+func (goini *Goini) Reload() error {
+	goini.mu.RLock()
+	loader := goini.loader
+	options := append([]Option{}, goini.options...)
+	validators := append([]func(*Goini) error{}, goini.validators...)
+	goini.mu.RUnlock()
+
+	if loader == nil {
+		return errors.New("goini: config cannot be reloaded")
+	}
+
+	next, err := loader(options)
+	if err != nil {
+		return err
+	}
+
+	for _, fn := range validators {
+		if err := fn(next); err != nil {
+			return fmt.Errorf("goini: reload rejected: %w", err)
+		}
+	}
+
+	old := goini.swap(next)
+
+	goini.mu.RLock()
+	callbacks := append([]func(old, new *Goini){}, goini.onChange...)
+	goini.mu.RUnlock()
+
+	for _, fn := range callbacks {
+		fn(old, goini)
+	}
+
+	return nil
+}
+
+/**
+ * 收到信号时重新加载配置，默认监听 SIGHUP，适用于 systemd 的 ExecReload
+ * @param ctx context.Context 结束时停止监听
+ * @param onError func(err error) 重新加载失败时的回调，可以为空
+ * @param sigs ...os.Signal 要监听的信号
+ */
+func (goini *Goini) ReloadOnSignal(ctx context.Context, onError func(err error), sigs ...os.Signal) {
+	if len(sigs) == 0 {
+		sigs = reloadSignals
+	}
+
+	if len(sigs) == 0 {
+		return
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sigs...)
+
+	go func() {
+		defer signal.Stop(ch)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ch:
+				if err := goini.Reload(); err != nil && onError != nil {
+					onError(err)
+				}
+			}
+		}
+	}()
+}
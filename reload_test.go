@@ -0,0 +1,95 @@
+package goini
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGoini_Reload(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "app.ini")
+	ioutil.WriteFile(path, []byte("port = 8080\n"), 0644)
+
+	config, err := LoadFile(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errPort := errors.New("port out of range")
+	config.AddValidator(func(next *Goini) error {
+		if port := next.GetInt("port"); port <= 0 || port > 65535 {
+			return errPort
+		}
+
+		return nil
+	})
+
+	changed := 0
+	config.OnChange(func(old, new *Goini) {
+		changed++
+	})
+
+	ioutil.WriteFile(path, []byte("port = 80x\n"), 0644)
+	if err := config.Reload(); !errors.Is(err, errPort) {
+		t.Errorf("Goini: Not as expected err=%v", err)
+	}
+
+	if ret := config.GetInt("port"); ret != 8080 || changed != 0 {
+		t.Errorf("Goini: Not as expected ret=%v, changed=%v", ret, changed)
+	}
+
+	ioutil.WriteFile(path, []byte("port = [80\n"), 0644)
+	var parseErr *ParseError
+	if err := config.Reload(); !errors.As(err, &parseErr) {
+		t.Errorf("Goini: Not as expected err=%v", err)
+	}
+
+	ioutil.WriteFile(path, []byte("port = 9090\n"), 0644)
+	if err := config.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	if ret := config.GetInt("port"); ret != 9090 || changed != 1 {
+		t.Errorf("Goini: Not as expected ret=%v, changed=%v", ret, changed)
+	}
+
+	config, _ = LoadString("port = 1", "ini")
+	if err := config.Reload(); err == nil {
+		t.Errorf("Goini: Not as expected err=%v", err)
+	}
+}
+
+func TestReload_EnvExpansion(t *testing.T) {
+	t.Setenv("GOINI_HOME", "/home/goini")
+	t.Setenv("GOINI_SECRET", "secret")
+
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "app.ini")
+	ioutil.WriteFile(path, []byte("home = ${env:GOINI_HOME}\nsecret = ${env:GOINI_SECRET}\n"), 0644)
+
+	config, err := LoadFile(path, "", WithEnvExpansion(AllowEnv("GOINI_HOME")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 回调中的旧配置与新配置使用相同的环境变量读取权限
+	var ret []string
+	config.OnChange(func(old, new *Goini) {
+		ret = append(ret, old.GetString("home"), old.GetString("secret"), new.GetString("secret"))
+	})
+
+	ioutil.WriteFile(path, []byte("home = ${env:GOINI_HOME}\nsecret = ${env:GOINI_SECRET}\nport = 80\n"), 0644)
+	if err := config.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []string{"/home/goini", "${env:GOINI_SECRET}", "${env:GOINI_SECRET}"}
+	if strings.Join(ret, ",") != strings.Join(expect, ",") {
+		t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, expect)
+	}
+}
@@ -0,0 +1,12 @@
+//go:build !js
+// +build !js
+
+package goini
+
+import (
+	"os"
+	"syscall"
+)
+
+// 默认触发重新加载的信号
+var reloadSignals = []os.Signal{syscall.SIGHUP}
@@ -0,0 +1,6 @@
+package goini
+
+import "os"
+
+// js 平台不支持 SIGHUP
+var reloadSignals = []os.Signal{}
@@ -0,0 +1,49 @@
+//go:build !windows && !js && !plan9
+// +build !windows,!js,!plan9
+
+package goini
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestGoini_ReloadOnSignal(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "app.ini")
+	ioutil.WriteFile(path, []byte("port = 8080\n"), 0644)
+
+	config, err := LoadFile(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changed := make(chan int64, 1)
+	config.OnChange(func(old, new *Goini) {
+		changed <- new.GetInt("port")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	config.ReloadOnSignal(ctx, func(err error) {
+		t.Error(err)
+	})
+
+	ioutil.WriteFile(path, []byte("port = 9090\n"), 0644)
+	syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
+
+	select {
+	case ret := <-changed:
+		if ret != 9090 {
+			t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, 9090)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Goini: reload was not triggered by SIGHUP")
+	}
+}
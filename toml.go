@@ -24,31 +24,30 @@ type TomlLineNode struct {
 	MultiLine string
 }
 
-var tomlLineNode = TomlLineNode{
-	KeyName: defaultName,
-}
-
-var IdxMap = map[string]string{}
-
-// 设置全局map表内容
-func setTomlGlobalMapValue(keyName string, value interface{}) {
+// 设置当前节map表内容
+func (goini *Goini) setTomlGlobalMapValue(keyName string, value interface{}) {
 	//设置属性
-	setProperty(keyName, value)
+	goini.setProperty(keyName, value)
 	//设置值
 	//property[keyName] = value
 }
 
-func parseTomlLine(rowB []byte) {
+/**
+ * 解析toml每行数据
+ * @param rowB []byte 每行数据
+ * @return error 解析失败时返回 *ParseError
+ */
+func (goini *Goini) parseTomlLine(rowB []byte) error {
 	// 去除空白，空格等字符
 	rowStr := string(rowB)
 	trimStr := strings.TrimSpace(rowStr)
 	if trimStr == "" {
-		return
+		return nil
 	}
 
 	// 解析注释行
 	if strings.HasPrefix(trimStr, "#") {
-		return
+		return nil
 	}
 
 	if strings.HasPrefix(trimStr, "[") {
@@ -57,96 +56,113 @@ func parseTomlLine(rowB []byte) {
 
 	// 解析[[xxx]]
 	if strings.HasPrefix(trimStr, "[[") && strings.HasSuffix(trimStr, "]]") {
-		parseTomlArrayLine()
+		if err := goini.parseTomlArrayLine(); err != nil {
+			return goini.parseError(rowStr, err)
+		}
+
+		strArr, err := findSliceString(trimStr)
+		if err != nil {
+			return goini.parseError(rowStr, err)
+		}
 
-		strArr := findSliceString(trimStr)
 		if len(strArr) > 0 {
 			tempKey := strArr[0]
 			keyName := tempKey[2 : len(tempKey)-2]
 
-			tomlLineNode.KeyName = keyName
-			tomlLineNode.ParentKey = keyName
-			tomlLineNode.Operate = OpArrayTable
+			goini.tomlLineNode.KeyName = keyName
+			goini.tomlLineNode.ParentKey = keyName
+			goini.tomlLineNode.Operate = OpArrayTable
 
 			// 处理数组索引为平滑结构 arr[0][1][2]...[n] => 0.1.2...n
-			JoinIndex(keyName)
+			goini.joinIndex(keyName)
 
 			// 设置数组表格
-			// setArrayTable(keyName, "", "")
+			// goini.setArrayTable(keyName, "", "")
 
-			return
+			return nil
 		}
 	}
 
 	// 解析[xxx]行
-	if strings.HasPrefix(trimStr, "[") && strings.HasSuffix(trimStr, "]") && tomlLineNode.Operate != OpInLine {
-		strArr := findSliceString(trimStr)
+	if strings.HasPrefix(trimStr, "[") && strings.HasSuffix(trimStr, "]") && goini.tomlLineNode.Operate != OpInLine {
+		strArr, err := findSliceString(trimStr)
+		if err != nil {
+			return goini.parseError(rowStr, err)
+		}
+
 		if len(strArr) > 0 {
 			tempKey := strArr[0]
 			keyName := tempKey[1 : len(tempKey)-1]
 
-			if tomlLineNode.Operate == OpArrayTable || tomlLineNode.Operate == OpArrayTableChild {
-				tomlLineNode.KeyName = keyName
-				tomlLineNode.ParentKey = keyName
-				tomlLineNode.Operate = OpArrayTableChild
+			if goini.tomlLineNode.Operate == OpArrayTable || goini.tomlLineNode.Operate == OpArrayTableChild {
+				goini.tomlLineNode.KeyName = keyName
+				goini.tomlLineNode.ParentKey = keyName
+				goini.tomlLineNode.Operate = OpArrayTableChild
 
-				return
+				return nil
 			}
 
 			// 重置
-			tomlLineNode = TomlLineNode{}
+			goini.tomlLineNode = TomlLineNode{}
 
-			tomlLineNode.KeyName = keyName
-			tomlLineNode.ParentKey = keyName
+			goini.tomlLineNode.KeyName = keyName
+			goini.tomlLineNode.ParentKey = keyName
 
-			return
+			return nil
 		}
 	}
 
+	// 表名未闭合
+	if strings.HasPrefix(trimStr, "[") && goini.tomlLineNode.MultiLine == "" {
+		return goini.parseError(rowStr, ErrSectionNotClosed)
+	}
+
 	// 拼装多行为一行数据
-	if tomlLineNode.MultiLine == OpInLine || tomlLineNode.MultiLine == OpNewLine {
-		if joinMultiLine(trimStr) {
-			pos := strings.LastIndexAny(tomlLineNode.KeyName, ".")
-			keyName := tomlLineNode.KeyName
+	if goini.tomlLineNode.MultiLine == OpInLine || goini.tomlLineNode.MultiLine == OpNewLine {
+		if goini.joinMultiLine(trimStr) {
+			pos := strings.LastIndexAny(goini.tomlLineNode.KeyName, ".")
+			keyName := goini.tomlLineNode.KeyName
 			if pos > -1 {
-				keyName = tomlLineNode.KeyName[pos+1:]
+				keyName = goini.tomlLineNode.KeyName[pos+1:]
 			}
 
-			tomlLineNode.KeyName = tomlLineNode.ParentKey
-			trimStr = keyName + "=" + tomlLineNode.Data
+			goini.tomlLineNode.KeyName = goini.tomlLineNode.ParentKey
+			trimStr = keyName + "=" + goini.tomlLineNode.Data
 		} else {
-			return
+			return nil
 		}
 	}
 
 	if rxNode.MatchString(trimStr) {
-		parseKv(trimStr)
+		return goini.parseError(rowStr, goini.parseKv(trimStr))
 	}
+
+	return nil
 }
 
 // 解析多行数据
-func joinMultiLine(rowStr string) bool {
+func (goini *Goini) joinMultiLine(rowStr string) bool {
 	trimStr := strings.TrimSpace(rowStr)
 	trimStrComment := RemoveComments(trimStr)
 	trimStrQuote := TrimQuote(trimStrComment)
 
-	if tomlLineNode.MultiLine == OpInLine || tomlLineNode.MultiLine == OpNewLine {
+	if goini.tomlLineNode.MultiLine == OpInLine || goini.tomlLineNode.MultiLine == OpNewLine {
 		if strings.HasSuffix(trimStrComment, "\"\"\"") || strings.HasSuffix(trimStrComment, "'''") {
-			tomlLineNode.Data += trimStrComment[:len(trimStrComment)-3]
+			goini.tomlLineNode.Data += trimStrComment[:len(trimStrComment)-3]
 
 			return true
 		} else if trimStrQuote == "]" {
-			tomlLineNode.Data += trimStrQuote
-			tomlLineNode.MultiLine = OpArrayLine
+			goini.tomlLineNode.Data += trimStrQuote
+			goini.tomlLineNode.MultiLine = OpArrayLine
 
 			return true
 		} else {
-			if strings.HasSuffix(trimStrQuote, "\\") || strings.HasPrefix(tomlLineNode.Data, "[") {
+			if strings.HasSuffix(trimStrQuote, "\\") || strings.HasPrefix(goini.tomlLineNode.Data, "[") {
 				trimStr = strings.TrimRight(trimStrQuote, "\\")
-				tomlLineNode.Data += trimStr
+				goini.tomlLineNode.Data += trimStr
 			} else {
-				tomlLineNode.Data += trimStrQuote + "\n"
-				tomlLineNode.MultiLine = OpNewLine
+				goini.tomlLineNode.Data += trimStrQuote + "\n"
+				goini.tomlLineNode.MultiLine = OpNewLine
 			}
 		}
 	}
@@ -155,32 +171,43 @@ func joinMultiLine(rowStr string) bool {
 }
 
 // 设置值，并重置
-func stringSetAndReset() {
-	tomlLineNode.Data = ""
-	tomlLineNode.KeyName = tomlLineNode.ParentKey
-	tomlLineNode.MultiLine = ""
+func (goini *Goini) stringSetAndReset() {
+	goini.tomlLineNode.Data = ""
+	goini.tomlLineNode.KeyName = goini.tomlLineNode.ParentKey
+	goini.tomlLineNode.MultiLine = ""
 }
 
-func parseTomlArrayLine() {
-	if tomlLineNode.MultiLine == OpArrayLine {
-		strArr := findSliceString(tomlLineNode.Data)
+func (goini *Goini) parseTomlArrayLine() error {
+	if goini.tomlLineNode.MultiLine == OpArrayLine {
+		strArr, err := findSliceString(goini.tomlLineNode.Data)
+		if err != nil {
+			return err
+		}
+
 		if len(strArr) > 0 {
-			retSlice := parseInlineSliceRow(tomlLineNode.KeyName, tomlLineNode.Data, 0)
-			setTomlGlobalMapValue(tomlLineNode.KeyName, retSlice)
+			retSlice, err := parseInlineSliceRow(goini.tomlLineNode.KeyName, goini.tomlLineNode.Data, 0)
+			if err != nil {
+				return err
+			}
+
+			goini.setTomlGlobalMapValue(goini.tomlLineNode.KeyName, retSlice)
 		}
 
 		// 重置
-		tomlLineNode.Data = ""
-		tomlLineNode.KeyName = tomlLineNode.ParentKey
-		tomlLineNode.MultiLine = ""
+		goini.tomlLineNode.Data = ""
+		goini.tomlLineNode.KeyName = goini.tomlLineNode.ParentKey
+		goini.tomlLineNode.MultiLine = ""
 	}
+
+	return nil
 }
 
 /**
  * 解析key = value 格式
  * @param rowStr string
+ * @return error
  */
-func parseKv(rowStr string) {
+func (goini *Goini) parseKv(rowStr string) error {
 	posEq := strings.IndexAny(rowStr, "=")
 
 	if posEq != -1 {
@@ -194,21 +221,31 @@ func parseKv(rowStr string) {
 		trimStrComment := RemoveComments(trimStr)
 		trimStrQuote := TrimQuote(trimStrComment)
 
-		newKeyName := tomlLineNode.KeyName + "." + keyName
+		newKeyName := goini.tomlLineNode.KeyName + "." + keyName
+
+		if goini.tomlLineNode.MultiLine == OpInLine || goini.tomlLineNode.MultiLine == OpNewLine || goini.tomlLineNode.MultiLine == OpArrayLine {
+			if goini.tomlLineNode.Operate == "" {
+				if goini.tomlLineNode.MultiLine == OpArrayLine {
+					v, ok, err := parseSliceValue(keyName, trimStrComment)
+					if err != nil {
+						return err
+					}
 
-		if tomlLineNode.MultiLine == OpInLine || tomlLineNode.MultiLine == OpNewLine || tomlLineNode.MultiLine == OpArrayLine {
-			if tomlLineNode.Operate == "" {
-				if tomlLineNode.MultiLine == OpArrayLine {
-					if v, ok := parseSliceValue(keyName, trimStrComment); ok {
-						setTomlGlobalMapValue(newKeyName, v)
+					if ok {
+						goini.setTomlGlobalMapValue(newKeyName, v)
 					}
 				} else {
-					setTomlGlobalMapValue(newKeyName, trimStrComment)
+					goini.setTomlGlobalMapValue(newKeyName, trimStrComment)
 				}
 			} else {
 				var rowVal interface{}
 				// 处理数组
-				if v, ok := parseSliceValue(newKeyName, trimStr); ok {
+				v, ok, err := parseSliceValue(newKeyName, trimStr)
+				if err != nil {
+					return err
+				}
+
+				if ok {
 					rowVal = v
 				}
 
@@ -216,11 +253,11 @@ func parseKv(rowStr string) {
 					rowVal = trimStrQuote
 				}
 
-				setArrayTable(tomlLineNode.KeyName, keyName, rowVal)
+				goini.setArrayTable(goini.tomlLineNode.KeyName, keyName, rowVal)
 			}
 
-			stringSetAndReset()
-			return
+			goini.stringSetAndReset()
+			return nil
 		}
 
 		// string 单行
@@ -229,42 +266,52 @@ func parseKv(rowStr string) {
 
 			strLen := len(trimStrComment)
 			if strLen > 6 {
-				keyName = tomlLineNode.ParentKey + "." + keyName
-				setTomlGlobalMapValue(keyName, trimStrComment[3:len(trimStrComment)-3])
-				tomlLineNode.Operate = ""
-				tomlLineNode.Data = ""
-				return
+				keyName = goini.tomlLineNode.ParentKey + "." + keyName
+				goini.setTomlGlobalMapValue(keyName, trimStrComment[3:len(trimStrComment)-3])
+				goini.tomlLineNode.Operate = ""
+				goini.tomlLineNode.Data = ""
+				return nil
 			}
 		}
 
 		// string 多行
 		if strings.HasPrefix(trimStrComment, "\"\"\"") || strings.HasPrefix(trimStrComment, "'''") {
-			tomlLineNode.KeyName = tomlLineNode.ParentKey + "." + keyName
+			goini.tomlLineNode.KeyName = goini.tomlLineNode.ParentKey + "." + keyName
 			if len(trimStrComment) >= 3 {
-				tomlLineNode.MultiLine = OpInLine
+				goini.tomlLineNode.MultiLine = OpInLine
 			}
 
-			return
+			return nil
 		}
 
 		// 处理数组换行
 		if strings.HasPrefix(trimStrComment, "[") && !strings.HasSuffix(trimStrComment, "]") {
-			tomlLineNode.KeyName = tomlLineNode.ParentKey + "." + keyName
-			tomlLineNode.MultiLine = OpInLine
-			tomlLineNode.Data = trimStrQuote
-			return
+			goini.tomlLineNode.KeyName = goini.tomlLineNode.ParentKey + "." + keyName
+			goini.tomlLineNode.MultiLine = OpInLine
+			goini.tomlLineNode.Data = trimStrQuote
+			return nil
 		}
 
 		// 表格数组
-		if tomlLineNode.Operate == OpArrayTable || tomlLineNode.Operate == OpArrayTableChild {
+		if goini.tomlLineNode.Operate == OpArrayTable || goini.tomlLineNode.Operate == OpArrayTableChild {
 			// 处理内联表
 			var rowVal interface{}
-			if v, ok := parseMapValue(keyName, trimStr); ok {
+			v, ok, err := parseMapValue(keyName, trimStr)
+			if err != nil {
+				return err
+			}
+
+			if ok {
 				rowVal = v
 			}
 
 			// 处理数组
-			if v, ok := parseSliceValue(newKeyName, trimStr); ok {
+			v, ok, err = parseSliceValue(newKeyName, trimStr)
+			if err != nil {
+				return err
+			}
+
+			if ok {
 				rowVal = v
 			}
 
@@ -272,71 +319,89 @@ func parseKv(rowStr string) {
 				rowVal = trimStrQuote
 			}
 
-			setArrayTable(tomlLineNode.KeyName, keyName, rowVal)
-			return
+			goini.setArrayTable(goini.tomlLineNode.KeyName, keyName, rowVal)
+			return nil
 		}
 
 		// 处理内联表
-		if v, ok := parseMapValue(tomlLineNode.KeyName, trimStrComment); ok {
-			setTomlGlobalMapValue(newKeyName, v)
-			return
+		v, ok, err := parseMapValue(goini.tomlLineNode.KeyName, trimStrComment)
+		if err != nil {
+			return err
+		}
+
+		if ok {
+			goini.setTomlGlobalMapValue(newKeyName, v)
+			return nil
 		}
 
 		// 处理变量引用
 		if strings.HasPrefix(trimStrQuote, "${") {
-			if parseVariate(newKeyName, trimStrQuote) {
-				return
+			if goini.parseVariate(newKeyName, trimStrQuote) {
+				return nil
 			}
 		}
 
 		// 处理数组
-		if v, ok := parseSliceValue(newKeyName, trimStrComment); ok {
-			setTomlGlobalMapValue(newKeyName, v)
-			return
+		v, ok, err = parseSliceValue(newKeyName, trimStrComment)
+		if err != nil {
+			return err
+		}
+
+		if ok {
+			goini.setTomlGlobalMapValue(newKeyName, v)
+			return nil
 		}
 
 		// 无特殊情况数值
-		if tomlLineNode.KeyName == defaultName {
+		if goini.tomlLineNode.KeyName == defaultName {
 			newKeyName = keyName
 		}
 
-		setTomlGlobalMapValue(newKeyName, trimStrQuote)
+		goini.setTomlGlobalMapValue(newKeyName, trimStrQuote)
 	}
+
+	return nil
 }
 
 // 解析素组类型的值
-func parseSliceValue(keyName, lineValue string) (interface{}, bool) {
+func parseSliceValue(keyName, lineValue string) (interface{}, bool, error) {
 	if strings.HasPrefix(lineValue, "[") {
-		strArr := findSliceString(lineValue)
+		strArr, err := findSliceString(lineValue)
+		if err != nil {
+			return nil, false, err
+		}
+
 		if len(strArr) > 0 {
-			return parseInlineSliceRow(keyName, lineValue, 0), true
+			retSlice, err := parseInlineSliceRow(keyName, lineValue, 0)
+			return retSlice, err == nil, err
 		}
 	}
 
-	return nil, false
+	return nil, false, nil
 }
 
 // 解析内联表类型的值
-func parseMapValue(keyName, lineValue string) (interface{}, bool) {
+func parseMapValue(keyName, lineValue string) (interface{}, bool, error) {
 	if strings.HasPrefix(lineValue, "{") && strings.HasSuffix(lineValue, "}") {
 		if strings.Index(lineValue, "=") != -1 {
-			return parseInlineRow(keyName, lineValue, 0, nil), true
+			retMap, err := parseInlineRow(keyName, lineValue, 0, nil)
+			return retMap, err == nil, err
 		}
 	}
 
-	return nil, false
+	return nil, false, nil
 }
 
 // 解析嵌套数组表格
-func parseArrayTable(rootKey, currentKey string, valueStr interface{}, keyDepth, depth int, obj interface{}, keyArr []string) interface{} {
+func (goini *Goini) parseArrayTable(rootKey, currentKey string, valueStr interface{}, keyDepth, depth int, obj interface{}, keyArr []string) interface{} {
 	if obj == nil {
 		if depth == 0 {
 			tempRootKey := keyArr[depth]
-			obj = getValBySection(tempRootKey, "")
+			obj = goini.getValBySection(tempRootKey, "")
 			if obj == nil {
 				obj = make(map[string]interface{})
 				// 如果根值是数组，则进行数组初始化
-				if tomlLineNode.Operate == OpArrayTable || tomlLineNode.Operate == OpArrayTableChild {
+				if goini.tomlLineNode.Operate == OpArrayTable || goini.tomlLineNode.Operate == OpArrayTableChild {
 					newArr := make([]map[string]interface{}, 0)
 					newMap := make(map[string]interface{})
 					newArr = append(newArr, newMap)
@@ -373,7 +438,7 @@ func parseArrayTable(rootKey, currentKey string, valueStr interface{}, keyDepth,
 			v := mp[rootKey]
 			// 若当前key的map没有值，则进行初始化
 			if v == nil {
-				if tomlLineNode.Operate == OpArrayTable {
+				if goini.tomlLineNode.Operate == OpArrayTable {
 					newArr := make([]map[string]interface{}, 0)
 					newMap := make(map[string]interface{})
 
@@ -395,11 +460,11 @@ func parseArrayTable(rootKey, currentKey string, valueStr interface{}, keyDepth,
 				}
 			} else {
 				// 若有值，并且是数组类型，则根据索引进行初始化
-				if tomlLineNode.Operate == OpArrayTable {
+				if goini.tomlLineNode.Operate == OpArrayTable {
 					if arr, ok := v.([]map[string]interface{}); ok {
 						arrLen := len(arr)
 
-						strIdxArr := strings.Split(tomlLineNode.ArrDepth, ".")
+						strIdxArr := strings.Split(goini.tomlLineNode.ArrDepth, ".")
 						strIdx := strIdxArr[depth]
 						iIdx, _ := strconv.Atoi(strIdx)
 
@@ -441,7 +506,7 @@ func parseArrayTable(rootKey, currentKey string, valueStr interface{}, keyDepth,
 				nextObj = v
 			} else {
 				if arr, ok := v.([]map[string]interface{}); ok {
-					strIdxArr := strings.Split(tomlLineNode.ArrDepth, ".")
+					strIdxArr := strings.Split(goini.tomlLineNode.ArrDepth, ".")
 					strIdx := strIdxArr[depth]
 					iIdx, _ := strconv.Atoi(strIdx)
 
@@ -487,7 +552,7 @@ func parseArrayTable(rootKey, currentKey string, valueStr interface{}, keyDepth,
 		}
 
 		// 递归解析
-		parseArrayTable(rootKey, currentKey, valueStr, keyDepth, depth+1, nextObj, keyArr)
+		goini.parseArrayTable(rootKey, currentKey, valueStr, keyDepth, depth+1, nextObj, keyArr)
 	}
 
 	return obj
@@ -519,22 +584,41 @@ func GetRootKey(str string) string {
 	return rootKey
 }
 
-// 多维数组索引平滑处理 arr[0][1][2] => 0.1.2.3.4
+// IdxMap 包级函数 JoinIndex 使用的数组表格索引
+//
+// Deprecated: 解析时的索引保存在每个 Goini 对象中，解析不再读写该变量
+var IdxMap = map[string]string{}
+
+// 供 JoinIndex 使用的对象
+var legacyIndex = newGoini("", "toml")
+
+// JoinIndex 计算数组表格的索引并记录在 IdxMap 中
+//
+// Deprecated: 解析toml时每个 Goini 对象会自行处理数组表格的索引
 func JoinIndex(keyName string) {
+	legacyIndex.mu.Lock()
+	defer legacyIndex.mu.Unlock()
+
+	legacyIndex.idxMap = IdxMap
+	legacyIndex.joinIndex(keyName)
+}
+
+// 多维数组索引平滑处理 arr[0][1][2] => 0.1.2.3.4
+func (goini *Goini) joinIndex(keyName string) {
 	rootKey := GetRootKey(keyName)
-	if idxStr, ok := IdxMap[rootKey]; ok {
-		tomlLineNode.ArrDepth = idxStr
+	if idxStr, ok := goini.idxMap[rootKey]; ok {
+		goini.tomlLineNode.ArrDepth = idxStr
 	} else {
-		IdxMap[rootKey] = ""
-		tomlLineNode.ArrDepth = ""
+		goini.idxMap[rootKey] = ""
+		goini.tomlLineNode.ArrDepth = ""
 	}
 
 	num := CountChar(keyName, ".")
-	if tomlLineNode.ArrDepth == "" {
-		tomlLineNode.ArrDepth = "0"
+	if goini.tomlLineNode.ArrDepth == "" {
+		goini.tomlLineNode.ArrDepth = "0"
 	}
 
-	strIdxArr := strings.Split(tomlLineNode.ArrDepth, ".")
+	strIdxArr := strings.Split(goini.tomlLineNode.ArrDepth, ".")
 	strIdxArrLen := len(strIdxArr)
 	if strIdxArrLen <= num {
 		for i := strIdxArrLen; i <= num; i++ {
@@ -551,26 +635,26 @@ func JoinIndex(keyName string) {
 	iIdx++
 	strIdxArr[num] = strconv.Itoa(iIdx)
 
-	tomlLineNode.ArrDepth = strings.Join(strIdxArr, ".")
+	goini.tomlLineNode.ArrDepth = strings.Join(strIdxArr, ".")
 
-	IdxMap[rootKey] = tomlLineNode.ArrDepth
+	goini.idxMap[rootKey] = goini.tomlLineNode.ArrDepth
 }
 
 // 设置数组表格
-func setArrayTable(keyName, valueKey string, valueStr interface{}) {
+func (goini *Goini) setArrayTable(keyName, valueKey string, valueStr interface{}) {
 	keyArr := strings.Split(keyName, ".")
-	obj := parseArrayTable(keyName, valueKey, valueStr, len(keyArr)-1, 0, nil, keyArr)
+	obj := goini.parseArrayTable(keyName, valueKey, valueStr, len(keyArr)-1, 0, nil, keyArr)
 
 	// 解析时增加了一层处理，这里需要还原回来
 	if mp, ok := obj.(map[string]interface{}); ok {
 		obj = mp[keyArr[0]]
 	}
 
-	setTomlGlobalMapValue(keyArr[0], obj)
+	goini.setTomlGlobalMapValue(keyArr[0], obj)
 }
 
 // 解析flow格式数据
-func parseInlineRow(keyName, valStr string, depth int, mp map[string]interface{}) interface{} {
+func parseInlineRow(keyName, valStr string, depth int, mp map[string]interface{}) (interface{}, error) {
 	if mp == nil {
 		mp = make(map[string]interface{})
 	}
@@ -578,7 +662,11 @@ func parseInlineRow(keyName, valStr string, depth int, mp map[string]interface{}
 	strLen := len(valStr)
 	valStr = valStr[1 : strLen-1]
 
-	nextFlowArr := findFlowString(valStr)
+	nextFlowArr, err := findFlowString(valStr)
+	if err != nil {
+		return nil, err
+	}
+
 	tempMap := make(map[string]string)
 	for idx, v := range nextFlowArr {
 		if strings.Index(v, "=") != -1 { // 含有kv形式的进行替换，待递归处理
@@ -595,7 +683,11 @@ func parseInlineRow(keyName, valStr string, depth int, mp map[string]interface{}
 	}
 
 	// 如果含有数组
-	sliceArr := findSliceString(valStr)
+	sliceArr, err := findSliceString(valStr)
+	if err != nil {
+		return nil, err
+	}
+
 	tempSliceMap := make(map[string]string)
 	if len(sliceArr) > 0 {
 		for idx, v := range sliceArr {
@@ -615,12 +707,19 @@ func parseInlineRow(keyName, valStr string, depth int, mp map[string]interface{}
 				v = TrimQuote(v)
 				//nextKey := keyName + "." + k
 				if nextV, ok := tempMap[v]; ok {
-					parseInlineRow(k, nextV, depth+1, m)
+					if _, err := parseInlineRow(k, nextV, depth+1, m); err != nil {
+						return nil, err
+					}
+
 					continue
 				}
 
 				if sliceStr, ok := tempSliceMap[v]; ok {
-					retSlice := parseInlineSliceRow(k, sliceStr, 0)
+					retSlice, err := parseInlineSliceRow(k, sliceStr, 0)
+					if err != nil {
+						return nil, err
+					}
+
 					m[k] = retSlice
 
 					continue
@@ -631,28 +730,40 @@ func parseInlineRow(keyName, valStr string, depth int, mp map[string]interface{}
 		}
 	}
 
-	return mp[keyName]
+	return mp[keyName], nil
 }
 
 // 解析flow格式数据
-func parseInlineSliceRow(keyName, valStr string, depth int) []interface{} {
+func parseInlineSliceRow(keyName, valStr string, depth int) ([]interface{}, error) {
 	var retSlice []interface{}
 
 	strLen := len(valStr)
 	valStr = valStr[1 : strLen-1]
 
 	// 如果含有内联表
-	flowArr := findFlowString(valStr)
+	flowArr, err := findFlowString(valStr)
+	if err != nil {
+		return nil, err
+	}
+
 	if len(flowArr) > 0 {
 		for _, flowStr := range flowArr {
-			retMap := parseInlineRow(keyName, flowStr, 0, nil)
+			retMap, err := parseInlineRow(keyName, flowStr, 0, nil)
+			if err != nil {
+				return nil, err
+			}
+
 			retSlice = append(retSlice, retMap)
 		}
 
-		return retSlice
+		return retSlice, nil
+	}
+
+	nextFlowArr, err := findSliceString(valStr)
+	if err != nil {
+		return nil, err
 	}
 
-	nextFlowArr := findSliceString(valStr)
 	tempMap := make(map[string]string)
 	for idx, v := range nextFlowArr {
 		if strings.HasPrefix(v, "[") { // 含有kv形式的进行替换，待递归处理
@@ -669,7 +780,11 @@ func parseInlineSliceRow(keyName, valStr string, depth int) []interface{} {
 		valStr = TrimQuote(valStr)
 
 		if val, ok := tempMap[valStr]; ok {
-			nextVal := parseInlineSliceRow(keyName, val, depth+1)
+			nextVal, err := parseInlineSliceRow(keyName, val, depth+1)
+			if err != nil {
+				return nil, err
+			}
+
 			retSlice = append(retSlice, nextVal)
 			continue
 		}
@@ -677,7 +792,7 @@ func parseInlineSliceRow(keyName, valStr string, depth int) []interface{} {
 		retSlice = append(retSlice, valStr)
 	}
 
-	return retSlice
+	return retSlice, nil
 }
 
 // 去除首尾引号
@@ -689,10 +804,10 @@ func TrimQuote(dest string) string {
 		return dest
 	}
 
-	// 去掉 " 引号
+	// 去掉 " 引号，还原转义的字符
 	if destB[0] == 34 && destB[strLen-1] == 34 {
 		destB = destB[1 : strLen-1]
-		return string(destB)
+		return unescapeString(string(destB))
 	}
 
 	// 去掉 ' 引号
@@ -704,25 +819,23 @@ func TrimQuote(dest string) string {
 	return string(destB)
 }
 
-// 获取引号符号情况下的注释开始位置
+// 获取引号符号情况下的注释开始位置，引号中的注释符号及双引号中转义的引号不计算在内
 func GetQuoteCommentIndex(destB []byte, target, char byte) int {
-	num := 0
-	pos := -1
-	for idx, v := range destB {
-		if v == char {
-			num++
-		}
-
-		if v == target {
-			pos = idx
+	inQuote := false
+	for idx := 0; idx < len(destB); idx++ {
+		v := destB[idx]
 
-			if num&1 == 0 {
-				break
-			}
+		switch {
+		case inQuote && char == 34 && v == 92:
+			idx++
+		case v == char:
+			inQuote = !inQuote
+		case v == target && !inQuote:
+			return idx
 		}
 	}
 
-	return pos
+	return -1
 }
 
 // 获取闭合符号情况下的注释开始位置
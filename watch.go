@@ -0,0 +1,208 @@
+package goini
+
+import (
+	"context"
+	"errors"
+	"hash/crc32"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// 监听选项
+type WatchOptions struct {
+	// 轮询间隔，默认1秒
+	Interval time.Duration
+
+	// 防抖时间，文件停止变化超过该时间后才重新加载，默认200毫秒
+	Debounce time.Duration
+
+	// 重新加载失败时的回调，失败时保留原有配置
+	OnError func(err error)
+}
+
+// 文件状态，修改时间精度不足时依靠内容校验和判断变化
+type fileStamp struct {
+	exists   bool
+	size     int64
+	modTime  time.Time
+	checksum uint32
+}
+
+/**
+ * 注册配置变化的回调，old 为变化前配置的只读副本，new 为当前配置
+ * @param fn func(old, new *Goini)
+ */
+func (goini *Goini) OnChange(fn func(old, new *Goini)) {
+	goini.mu.Lock()
+	defer goini.mu.Unlock()
+
+	goini.onChange = append(goini.onChange, fn)
+}
+
+/**
+ * 轮询监听加载过的文件，文件变化时重新解析并替换当前配置，直到ctx结束
+ * 编辑器保存时的连续写入、重命名会在防抖时间内合并为一次加载
+ * @param ctx context.Context
+ * @param opts WatchOptions 监听选项
+ * @return error 没有可监听的文件时返回错误
+ */
+func (goini *Goini) Watch(ctx context.Context, opts WatchOptions) error {
+	if opts.Interval <= 0 {
+		opts.Interval = time.Second
+	}
+
+	if opts.Debounce <= 0 {
+		opts.Debounce = 200 * time.Millisecond
+	}
+
+	goini.mu.RLock()
+	files := append([]string{}, goini.files...)
+	loader := goini.loader
+	goini.mu.RUnlock()
+
+	if loader == nil || len(files) == 0 {
+		return errors.New("goini: nothing to watch")
+	}
+
+	last := goini.stampFiles(files)
+
+	go func() {
+		ticker := time.NewTicker(opts.Interval)
+		defer ticker.Stop()
+
+		// 最后一次发现变化的时间
+		var changedAt time.Time
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			current := goini.stampFiles(files)
+			if !sameStamps(last, current) {
+				last = current
+				changedAt = time.Now()
+				continue
+			}
+
+			if changedAt.IsZero() || time.Since(changedAt) < opts.Debounce {
+				continue
+			}
+
+			changedAt = time.Time{}
+
+			if err := goini.Reload(); err != nil && opts.OnError != nil {
+				opts.OnError(err)
+			}
+
+			// 重新加载后引入的文件可能发生变化
+			goini.mu.RLock()
+			files = append([]string{}, goini.files...)
+			goini.mu.RUnlock()
+
+			last = goini.stampFiles(files)
+		}
+	}()
+
+	return nil
+}
+
+/**
+ * 使用新配置替换当前的内容，返回替换前内容的副本
+ * @param next *Goini 新配置
+ * @return *Goini
+ */
+func (goini *Goini) swap(next *Goini) *Goini {
+	goini.mu.Lock()
+	defer goini.mu.Unlock()
+
+	old := newGoini(goini.filePath, "")
+	old.Syntax = goini.Syntax
+	old.sections = goini.sections
+	old.origins = goini.origins
+	old.files = goini.files
+	old.fsys = goini.fsys
+	old.doc = goini.doc
+	old.base = goini.base
+	old.inherits = goini.inherits
+	old.own = goini.own
+	old.options = goini.options
+	old.overrides = goini.overrides
+	old.envKeys = goini.envKeys
+	old.envAllow = goini.envAllow
+
+	goini.Syntax = next.Syntax
+	goini.sections = next.sections
+	goini.origins = next.origins
+	goini.files = next.files
+	goini.doc = next.doc
+	goini.base = next.base
+	goini.inherits = next.inherits
+	goini.own = next.own
+
+	return old
+}
+
+// 获取文件状态
+func (goini *Goini) stampFiles(files []string) map[string]fileStamp {
+	stamps := make(map[string]fileStamp, len(files))
+
+	for _, name := range files {
+		var info fs.FileInfo
+		var err error
+
+		if goini.fsys != nil {
+			info, err = fs.Stat(goini.fsys, name)
+		} else {
+			info, err = os.Stat(name)
+		}
+
+		if err != nil {
+			stamps[name] = fileStamp{}
+			continue
+		}
+
+		stamp := fileStamp{
+			exists:  true,
+			size:    info.Size(),
+			modTime: info.ModTime(),
+		}
+
+		if !info.IsDir() {
+			var content []byte
+			if goini.fsys != nil {
+				content, err = fs.ReadFile(goini.fsys, name)
+			} else {
+				content, err = os.ReadFile(name)
+			}
+
+			if err == nil {
+				stamp.checksum = crc32.ChecksumIEEE(content)
+			}
+		}
+
+		stamps[name] = stamp
+	}
+
+	return stamps
+}
+
+// 文件状态是否一致
+func sameStamps(a, b map[string]fileStamp) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for name, stamp := range a {
+		other, ok := b[name]
+		if !ok || other.exists != stamp.exists || other.size != stamp.size ||
+			!other.modTime.Equal(stamp.modTime) || other.checksum != stamp.checksum {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,58 @@
+package goini
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGoini_Watch(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "app.ini")
+	ioutil.WriteFile(path, []byte("port = 8080\n"), 0644)
+
+	config, err := LoadFile(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changed := make(chan [2]string, 1)
+	config.OnChange(func(old, new *Goini) {
+		changed <- [2]string{old.GetString("port"), new.GetString("port")}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := config.Watch(ctx, WatchOptions{Interval: 10 * time.Millisecond, Debounce: 30 * time.Millisecond}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 模拟编辑器保存：先写临时文件再重命名
+	tmp := filepath.Join(dir, ".app.ini.tmp")
+	ioutil.WriteFile(tmp, []byte("port = 9090\n"), 0644)
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case ret := <-changed:
+		if ret[0] != "8080" || ret[1] != "9090" {
+			t.Errorf("Goini: Not as expected ret=%v", ret)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Goini: change callback was not fired")
+	}
+
+	if ret := config.GetInt("port"); ret != 9090 {
+		t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, 9090)
+	}
+
+	if err := (&Goini{}).Watch(ctx, WatchOptions{}); err == nil {
+		t.Errorf("Goini: Not as expected err=%v", err)
+	}
+}
@@ -0,0 +1,246 @@
+package goini
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+/**
+ * 将配置以ini格式写入w，嵌套的map写为以.分隔的key，切片写为 [a, b] 形式
+ * 从ini文件加载的配置保留原有的注释、空行、引号及顺序，只改变 Set 修改过的行
+ * WithOverrides、WithEnv 等选项覆盖的值不会写入，仍写入文件中的值
+ * @param w io.Writer
+ * @return int64, error
+ */
+func (goini *Goini) WriteTo(w io.Writer) (int64, error) {
+	goini.mu.RLock()
+	buf, err := goini.encode()
+	goini.mu.RUnlock()
+
+	if err != nil {
+		return 0, err
+	}
+
+	return buf.WriteTo(w)
+}
+
+/**
+ * 以ini格式保存到文件，先写入临时文件再重命名，避免写入中断导致文件损坏
+ * @param path string 文件路径
+ * @return error
+ */
+func (goini *Goini) SaveFile(path string) error {
+	goini.mu.RLock()
+	buf, err := goini.encode()
+	goini.mu.RUnlock()
+
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(path, buf.Bytes())
+}
+
+// 编码为ini格式，从ini文件加载的配置保留原有的注释、空行及顺序
+func (goini *Goini) encode() (*bytes.Buffer, error) {
+	if goini.doc != nil {
+		if err := goini.doc.err(); err != nil {
+			return nil, err
+		}
+
+		return goini.doc.bytes(), nil
+	}
+
+	// 不写入覆盖的节点及环境变量的值
+	if goini.base != nil {
+		return encodeIni(goini.base)
+	}
+
+	return encodeIni(goini.sections)
+}
+
+// 原子写入文件
+func writeFileAtomic(path string, data []byte) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	fp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	tmpName := fp.Name()
+
+	// 失败时清理临时文件
+	defer func() {
+		if err != nil {
+			os.Remove(tmpName)
+		}
+	}()
+
+	if _, err = fp.Write(data); err != nil {
+		fp.Close()
+		return err
+	}
+
+	if err = fp.Sync(); err != nil {
+		fp.Close()
+		return err
+	}
+
+	if err = fp.Close(); err != nil {
+		return err
+	}
+
+	if err = os.Chmod(tmpName, mode); err != nil {
+		return err
+	}
+
+	err = os.Rename(tmpName, path)
+
+	return err
+}
+
+// 编码为ini格式，默认节在最前面且不写节名，其他节按名称排序
+func encodeIni(sections map[string]interface{}) (*bytes.Buffer, error) {
+	buf := &bytes.Buffer{}
+
+	names := make([]string, 0, len(sections))
+	for name := range sections {
+		if name != defaultName {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+	names = append([]string{defaultName}, names...)
+
+	for _, name := range names {
+		property, _ := sections[name].(map[string]interface{})
+		if name == defaultName && len(property) == 0 {
+			continue
+		}
+
+		if buf.Len() > 0 {
+			buf.WriteString("\n")
+		}
+
+		if name != defaultName {
+			buf.WriteString("[" + name + "]\n")
+		}
+
+		leaves := make(map[string]interface{})
+		flattenValue("", property, leaves)
+
+		keys := make([]string, 0, len(leaves))
+		for key := range leaves {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			valStr, err := formatIniValue(leaves[key])
+			if err != nil {
+				return nil, fmt.Errorf("goini: [%s] %s: %w", name, key, err)
+			}
+
+			buf.WriteString(key + " = " + valStr + "\n")
+		}
+	}
+
+	return buf, nil
+}
+
+// 展开嵌套的map，key以.连接
+func flattenValue(prefix string, val interface{}, leaves map[string]interface{}) {
+	if mp, ok := val.(map[string]interface{}); ok {
+		for k, v := range mp {
+			key := k
+			if prefix != "" {
+				key = prefix + "." + k
+			}
+
+			flattenValue(key, v, leaves)
+		}
+
+		return
+	}
+
+	if prefix != "" {
+		leaves[prefix] = val
+	}
+}
+
+// 格式化ini的值
+func formatIniValue(val interface{}) (string, error) {
+	switch v := val.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return quoteIniString(v)
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			if _, ok := item.([]interface{}); !ok {
+				if itemStr, ok := item.(string); ok && strings.ContainsAny(itemStr, ",[]") {
+					return "", fmt.Errorf("slice item %q cannot be written to ini", itemStr)
+				}
+			}
+
+			itemStr, err := formatIniValue(item)
+			if err != nil {
+				return "", err
+			}
+
+			items = append(items, itemStr)
+		}
+
+		return "[" + strings.Join(items, ", ") + "]", nil
+	case map[string]interface{}, []map[string]interface{}:
+		return "", fmt.Errorf("%T cannot be written to ini", val)
+	}
+
+	return quoteIniString(fmt.Sprintf("%v", val))
+}
+
+// 写入ini的值，必要时加上引号，[a, b] 形式的切片及已经加上引号的值保持原样
+func iniText(valStr string) (string, error) {
+	if strings.HasPrefix(valStr, "[") || quotedLen(valStr) == len(valStr) {
+		return valStr, nil
+	}
+
+	return quoteIniString(valStr)
+}
+
+// 必要时为字符串加上引号，引号中的内容原样读取，不支持转义，因此不能写入换行及同时含有两种引号的值
+func quoteIniString(valStr string) (string, error) {
+	if strings.ContainsAny(valStr, "\r\n") {
+		return "", fmt.Errorf("value %q contains a line break", valStr)
+	}
+
+	needQuote := valStr != strings.TrimSpace(valStr) ||
+		strings.ContainsAny(valStr, "#;\"'") ||
+		strings.HasPrefix(valStr, "[")
+
+	if !needQuote {
+		return valStr, nil
+	}
+
+	if !strings.Contains(valStr, "\"") {
+		return "\"" + valStr + "\"", nil
+	}
+
+	if !strings.Contains(valStr, "'") {
+		return "'" + valStr + "'", nil
+	}
+
+	return "", fmt.Errorf("value %q contains both quote styles", valStr)
+}
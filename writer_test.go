@@ -0,0 +1,81 @@
+package goini
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSaveFile(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "app.ini")
+	ioutil.WriteFile(path, []byte("env = test\nname = \" padded \"\n[db]\ndb.host = 127.0.0.1\ndb.port = 3306\nhosts = [a, b]\nnote = \"a # b\"\n"), 0644)
+
+	config, err := LoadFile(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config.Set("db.port", 3307, "db")
+	config.Set("cache.size", 10)
+
+	if err := config.SaveFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 1 {
+		t.Errorf("Goini: Not as expected ret=%v, expect=%v", len(files), 1)
+	}
+
+	saved, err := LoadFile(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		Key     string
+		Section string
+		Expect  string
+	}{
+		{Key: "env", Section: "default", Expect: "test"},
+		{Key: "name", Section: "default", Expect: " padded "},
+		{Key: "cache.size", Section: "default", Expect: "10"},
+		{Key: "db.host", Section: "db", Expect: "127.0.0.1"},
+		{Key: "db.port", Section: "db", Expect: "3307"},
+		{Key: "note", Section: "db", Expect: "a # b"},
+	}
+
+	for _, v := range testCases {
+		if ret := saved.GetString(v.Key, v.Section); ret != v.Expect {
+			t.Errorf("Goini: Not as expected ret=%v, expect=%v", ret, v.Expect)
+		}
+	}
+
+	var hosts []string
+	saved.GetSlice("hosts", ",", &hosts, "db")
+	if strings.Join(hosts, ",") != "a,b" {
+		t.Errorf("Goini: Not as expected ret=%v, expect=%v", hosts, "[a b]")
+	}
+}
+
+func TestWriteTo(t *testing.T) {
+	config, err := LoadString("db:\n  host: 127.0.0.1\n  port: 3306\n", "yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	n, err := config.WriteTo(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := "db.host = 127.0.0.1\ndb.port = 3306\n"
+	if buf.String() != expect || n != int64(len(expect)) {
+		t.Errorf("Goini: Not as expected ret=%q, expect=%q", buf.String(), expect)
+	}
+}
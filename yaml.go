@@ -16,38 +16,40 @@ type LineNode struct {
 	Operate  string
 }
 
-var lineNode LineNode
-
-// 设置全局map表内容
-func setGlobalMapValue(value interface{}) {
+// 设置当前节map表内容
+func (goini *Goini) setGlobalMapValue(value interface{}) {
 	//设置属性
-	setProperty(lineNode.KeyName, value)
+	goini.setProperty(goini.lineNode.KeyName, value)
 	//设置值
-	property[lineNode.KeyName] = value
+	goini.property[goini.lineNode.KeyName] = value
 }
 
-func parseYamlLine(rowB []byte) {
+/**
+ * 解析yaml每行数据
+ * @param rowB []byte 每行数据
+ * @return error 解析失败时返回 *ParseError
+ */
+func (goini *Goini) parseYamlLine(rowB []byte) error {
 	// 去除空白，空格等字符
 	rowStr := string(rowB)
 	trimStr := strings.TrimSpace(rowStr)
 	if trimStr == "" || trimStr == ":" {
-		return
+		return nil
 	}
 
 	// 解析注释行
 	if strings.HasPrefix(trimStr, "#") {
-		return
+		return nil
 	}
 
 	// 数组
 	if strings.Index(trimStr, "-") == 0 {
-		parseArrayLine(trimStr[1:])
-		return
+		return goini.parseError(rowStr, goini.parseArrayLine(trimStr[1:]))
 	}
 
 	// 重置状态
-	lineNode.List = nil
-	lineNode.Arr = nil
+	goini.lineNode.List = nil
+	goini.lineNode.Arr = nil
 
 	// 如果lineNode.Operate != " < 或 | ",
 	// 下一行内容如果含有:符号，肯能解析错误
@@ -55,17 +57,17 @@ func parseYamlLine(rowB []byte) {
 	if pos > 0 {
 		// 第一key
 		if rowB[0] != 32 {
-			lineNode.KeyName = trimStr[:pos]
-			lineNode.SpaceLen = 0
-			lineNode.KeyDepth = 1
+			goini.lineNode.KeyName = trimStr[:pos]
+			goini.lineNode.SpaceLen = 0
+			goini.lineNode.KeyDepth = 1
 		} else {
 			// 设置行内容
-			setLineNode(trimStr[:pos], rowB)
+			goini.setLineNode(trimStr[:pos], rowB)
 		}
 
 		// 重置 operate
-		lineNode.Operate = ""
-		lineNode.Data = ""
+		goini.lineNode.Operate = ""
+		goini.lineNode.Data = ""
 	}
 
 	rowValue := strings.TrimSpace(trimStr[pos+1:])
@@ -73,19 +75,32 @@ func parseYamlLine(rowB []byte) {
 	// 处理变量引用
 	if strings.HasPrefix(rowValue, "$") {
 		varStr := rxVariate.FindString(trimStr)
-		if varStr != "" && len(varStr) > 3 {
-			setGlobalMapValue(property[varStr[2:len(varStr)-1]])
-			return
+		if varStr != "" && len(varStr) > 3 && !goini.isEnvVariate(varStr[2:len(varStr)-1]) {
+			varName, _, hasModifier := splitVariable(varStr[2 : len(varStr)-1])
+
+			// 含有修饰符的变量未取到值时保留原样，取值时解析
+			if mixVal, ok := goini.property[varName]; !hasModifier || (ok && mixVal != "") {
+				goini.setGlobalMapValue(mixVal)
+				return nil
+			}
 		}
 	}
 
 	// 处理数组行
 	if strings.HasPrefix(rowValue, "[") {
-		strArr := findSliceString(rowValue)
+		strArr, err := findSliceString(rowValue)
+		if err != nil {
+			return goini.parseError(rowStr, err)
+		}
+
 		if len(strArr) > 0 {
-			retSlice := parseSliceRow(rowValue, 0)
-			setGlobalMapValue(retSlice)
-			return
+			retSlice, err := parseSliceRow(rowValue, 0)
+			if err != nil {
+				return goini.parseError(rowStr, err)
+			}
+
+			goini.setGlobalMapValue(retSlice)
+			return nil
 		}
 	}
 
@@ -93,83 +108,111 @@ func parseYamlLine(rowB []byte) {
 	if strings.HasPrefix(rowValue, "{") {
 		flowStr := rxYamlFlow.FindString(rowValue)
 		if strings.Index(flowStr, ":") != -1 {
-			parseFlowRow(lineNode.KeyName, flowStr, 0)
-			return
+			return goini.parseError(rowStr, goini.parseFlowRow(goini.lineNode.KeyName, flowStr, 0))
 		}
 	}
 
 	if rowValue != "" {
 		if rowValue == "|" || rowValue == ">" {
-			lineNode.Operate = rowValue
-			lineNode.Data = ""
-			return
+			goini.lineNode.Operate = rowValue
+			goini.lineNode.Data = ""
+			return nil
 		}
 
-		if lineNode.Operate == "|" {
-			if lineNode.Data == "" {
-				lineNode.Data = rowValue
+		if goini.lineNode.Operate == "|" {
+			if goini.lineNode.Data == "" {
+				goini.lineNode.Data = rowValue
 			} else {
-				lineNode.Data += "\n" + rowValue
+				goini.lineNode.Data += "\n" + rowValue
 			}
 
-			setGlobalMapValue(lineNode.Data)
-			return
+			goini.setGlobalMapValue(goini.lineNode.Data)
+			return nil
 		}
 
-		if lineNode.Operate == ">" {
-			if lineNode.Data == "" {
-				lineNode.Data = rowValue
+		if goini.lineNode.Operate == ">" {
+			if goini.lineNode.Data == "" {
+				goini.lineNode.Data = rowValue
 			} else {
-				lineNode.Data += " " + rowValue
+				goini.lineNode.Data += " " + rowValue
 			}
 
-			setGlobalMapValue(lineNode.Data)
-			return
+			goini.setGlobalMapValue(goini.lineNode.Data)
+			return nil
 		}
 
-		lineNode.Operate = ""
-		setValStr := parsNodeValue(rowValue)
-		setGlobalMapValue(setValStr)
+		goini.lineNode.Operate = ""
+		setValStr := parsYamlValue(rowValue)
+		goini.setGlobalMapValue(setValStr)
 	} else {
-		lineNode.Data = ""
-		lineNode.Operate = ""
+		goini.lineNode.Data = ""
+		goini.lineNode.Operate = ""
+	}
+
+	return nil
+}
+
+// 解析yml的值，双引号中支持 \n、\\、\" 等转义，其他与 parsNodeValue 相同
+func parsYamlValue(valueStr string) string {
+	valueStr = strings.TrimSpace(valueStr)
+
+	if strings.HasPrefix(valueStr, "\"") {
+		for i := 1; i < len(valueStr); i++ {
+			if valueStr[i] == '\\' {
+				i++
+			} else if valueStr[i] == '"' {
+				return unescapeString(valueStr[1:i])
+			}
+		}
 	}
+
+	return parsNodeValue(valueStr)
 }
 
 // 处理数组标识行
-func parseArrayLine(trimStr string) {
+func (goini *Goini) parseArrayLine(trimStr string) error {
 	valStr := strings.TrimSpace(trimStr)
-	valStr = parsNodeValue(valStr)
-	if lineNode.Data == "" {
-		lineNode.Data = valStr
+	valStr = parsYamlValue(valStr)
+	if goini.lineNode.Data == "" {
+		goini.lineNode.Data = valStr
 	} else {
-		lineNode.Data += "," + valStr
+		goini.lineNode.Data += "," + valStr
 	}
 
 	if strings.HasPrefix(valStr, "{") {
-		retMap := parseSliceFlowRowToMap(lineNode.KeyName, valStr, 0)
-		lineNode.List = append(lineNode.List, retMap)
+		retMap, err := parseSliceFlowRowToMap(goini.lineNode.KeyName, valStr, 0)
+		if err != nil {
+			return err
+		}
 
-		setGlobalMapValue(lineNode.List)
+		goini.lineNode.List = append(goini.lineNode.List, retMap)
 
-		return
+		goini.setGlobalMapValue(goini.lineNode.List)
+
+		return nil
 	}
 
 	// 是否是数组
 	if strings.HasPrefix(valStr, "[") { // 数组内容
-		retSlice := parseSliceRow(valStr, 0)
-		lineNode.Arr = append(lineNode.Arr, retSlice)
+		retSlice, err := parseSliceRow(valStr, 0)
+		if err != nil {
+			return err
+		}
 
-		setGlobalMapValue(lineNode.Arr)
+		goini.lineNode.Arr = append(goini.lineNode.Arr, retSlice)
 
-		return
+		goini.setGlobalMapValue(goini.lineNode.Arr)
+
+		return nil
 	}
 
-	setGlobalMapValue(lineNode.Data)
+	goini.setGlobalMapValue(goini.lineNode.Data)
+
+	return nil
 }
 
 // 设置行数据
-func setLineNode(rowStr string, rowB []byte) {
+func (goini *Goini) setLineNode(rowStr string, rowB []byte) {
 	// 从头计算空格长度
 	spaceLen := int64(0)
 	rowLen := len(rowB)
@@ -192,21 +235,21 @@ func setLineNode(rowStr string, rowB []byte) {
 		}
 	}
 
-	tempB := []byte(lineNode.KeyName)
+	tempB := []byte(goini.lineNode.KeyName)
 	periodCount := int64(0)
 	periodPos := 0
 
-	if lineNode.SpaceLen < spaceLen {
-		lineNode.KeyName += "." + rowStr
-		lineNode.SpaceLen = spaceLen
-		lineNode.KeyDepth++
-	} else if lineNode.SpaceLen >= spaceLen {
+	if goini.lineNode.SpaceLen < spaceLen {
+		goini.lineNode.KeyName += "." + rowStr
+		goini.lineNode.SpaceLen = spaceLen
+		goini.lineNode.KeyDepth++
+	} else if goini.lineNode.SpaceLen >= spaceLen {
 		if spaceLen == 1 {
 			spaceLen = 2
 		}
 
-		num := lineNode.SpaceLen / spaceLen
-		depth := lineNode.KeyDepth - num
+		num := goini.lineNode.SpaceLen / spaceLen
+		depth := goini.lineNode.KeyDepth - num
 		for i, v := range tempB {
 			// 计数空格
 			if v == 46 {
@@ -219,18 +262,22 @@ func setLineNode(rowStr string, rowB []byte) {
 			}
 		}
 
-		lineNode.KeyName = string(tempB[:periodPos]) + "." + rowStr
-		lineNode.KeyDepth = depth + 1
-		lineNode.SpaceLen = spaceLen
+		goini.lineNode.KeyName = string(tempB[:periodPos]) + "." + rowStr
+		goini.lineNode.KeyDepth = depth + 1
+		goini.lineNode.SpaceLen = spaceLen
 	}
 }
 
 // 解析flow格式数据
-func parseSliceFlowRowToMap(keyName, valStr string, depth int) map[string]interface{} {
+func parseSliceFlowRowToMap(keyName, valStr string, depth int) (map[string]interface{}, error) {
 	strLen := len(valStr)
 	valStr = valStr[1 : strLen-1]
 
-	nextFlowArr := findFlowString(valStr)
+	nextFlowArr, err := findFlowString(valStr)
+	if err != nil {
+		return nil, err
+	}
+
 	tempMap := make(map[string]string)
 	for idx, v := range nextFlowArr {
 		if strings.Index(v, ":") != -1 { // 含有kv形式的进行替换，待递归处理
@@ -250,7 +297,12 @@ func parseSliceFlowRowToMap(keyName, valStr string, depth int) map[string]interf
 
 			nextKey := k
 			if nextV, ok := tempMap[v]; ok {
-				ret[nextKey] = parseSliceFlowRowToMap(nextKey, nextV, depth+1)
+				nextMap, err := parseSliceFlowRowToMap(nextKey, nextV, depth+1)
+				if err != nil {
+					return nil, err
+				}
+
+				ret[nextKey] = nextMap
 				continue
 			}
 
@@ -258,15 +310,19 @@ func parseSliceFlowRowToMap(keyName, valStr string, depth int) map[string]interf
 		}
 	}
 
-	return ret
+	return ret, nil
 }
 
 // 解析flow格式数据
-func parseFlowRow(keyName, valStr string, depth int) {
+func (goini *Goini) parseFlowRow(keyName, valStr string, depth int) error {
 	strLen := len(valStr)
 	valStr = valStr[1 : strLen-1]
 
-	nextFlowArr := findFlowString(valStr)
+	nextFlowArr, err := findFlowString(valStr)
+	if err != nil {
+		return err
+	}
+
 	tempMap := make(map[string]string)
 	for idx, v := range nextFlowArr {
 		if strings.Index(v, ":") != -1 { // 含有kv形式的进行替换，待递归处理
@@ -284,21 +340,30 @@ func parseFlowRow(keyName, valStr string, depth int) {
 
 			nextKey := keyName + "." + k
 			if nextV, ok := tempMap[v]; ok {
-				parseFlowRow(nextKey, nextV, depth+1)
+				if err := goini.parseFlowRow(nextKey, nextV, depth+1); err != nil {
+					return err
+				}
+
 				continue
 			}
 
-			setGlobalMapValue(v)
+			goini.setGlobalMapValue(v)
 		}
 	}
+
+	return nil
 }
 
 // 解析flow格式数据
-func parseSliceRow(valStr string, depth int) []interface{} {
+func parseSliceRow(valStr string, depth int) ([]interface{}, error) {
 	strLen := len(valStr)
 	valStr = valStr[1 : strLen-1]
 
-	nextFlowArr := findSliceString(valStr)
+	nextFlowArr, err := findSliceString(valStr)
+	if err != nil {
+		return nil, err
+	}
+
 	tempMap := make(map[string]string)
 	for idx, v := range nextFlowArr {
 		if strings.HasPrefix(v, "[") { // 含有kv形式的进行替换，待递归处理
@@ -317,7 +382,11 @@ func parseSliceRow(valStr string, depth int) []interface{} {
 		valStr = TrimQuote(valStr)
 
 		if val, ok := tempMap[valStr]; ok {
-			nextVal := parseSliceRow(val, depth+1)
+			nextVal, err := parseSliceRow(val, depth+1)
+			if err != nil {
+				return nil, err
+			}
+
 			retSlice = append(retSlice, nextVal)
 			continue
 		}
@@ -325,39 +394,43 @@ func parseSliceRow(valStr string, depth int) []interface{} {
 		retSlice = append(retSlice, valStr)
 	}
 
-	return retSlice
+	return retSlice, nil
 }
 
 // 找出匹配flow格式的字符串，只匹配第一层
-func findFlowString(str string) []string {
+func findFlowString(str string) ([]string, error) {
 	return findClosureValue(str, 123, 125, "}", "{")
 }
 
 // 找出数组格式数据
-func findSliceString(str string) []string {
+func findSliceString(str string) ([]string, error) {
 	return findClosureValue(str, 91, 93, "]", "[")
 }
 
-func findClosureValue(str string, start, end byte, endSymbol, startSymbol string) []string {
+func findClosureValue(str string, start, end byte, endSymbol, startSymbol string) ([]string, error) {
 	strB := []byte(str)
 
 	var tempB []byte
 	var retArr []string
 
-	// 哨兵
-	guard := 0
+	// 记录未闭合的起始符号位置
+	var openPos []int
 
-	for _, v := range strB {
+	for idx, v := range strB {
 		if v == start {
-			guard++ // 放置哨兵
+			openPos = append(openPos, idx) // 放置哨兵
 			tempB = append(tempB, v)
 			continue
 		}
 
 		if v == end {
-			guard-- // 清除哨兵
+			if len(openPos) == 0 {
+				return nil, &symbolError{symbol: startSymbol, text: str, offset: idx}
+			}
+
+			openPos = openPos[:len(openPos)-1] // 清除哨兵
 			tempB = append(tempB, v)
-			if guard == 0 {
+			if len(openPos) == 0 {
 				retArr = append(retArr, string(tempB))
 				//重置空
 				tempB = []byte{}
@@ -365,22 +438,14 @@ func findClosureValue(str string, start, end byte, endSymbol, startSymbol string
 			continue
 		}
 
-		if guard > 0 {
+		if len(openPos) > 0 {
 			tempB = append(tempB, v)
 		}
 	}
 
-	if guard > 0 {
-		panic(" '" + endSymbol + "' Symbol mismatch")
-	}
-
-	if guard < 0 {
-		panic(" '" + startSymbol + "' Symbol mismatch")
+	if len(openPos) > 0 {
+		return nil, &symbolError{symbol: endSymbol, text: str, offset: openPos[len(openPos)-1]}
 	}
 
-	// 回收临时变量
-	tempB = []byte{}
-	strB = []byte{}
-
-	return retArr
+	return retArr, nil
 }